@@ -0,0 +1,135 @@
+// Package auth authenticates HTTP requests with JWT bearer tokens or signed
+// session cookies. Middleware stores the authenticated Principal in the
+// request context, where handlers read it with FromContext and route guards
+// such as RequireAuth and RequireRole check it. Policies registered per
+// model type with RegisterPolicy and permissions granted to roles decide
+// what the principal may do.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ThreadBolt/threadbolt/pkg/problem"
+)
+
+var (
+	// ErrNoCredentials is returned by an Authenticator when the request
+	// carries no credentials it understands.
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidToken is returned for malformed, forged or expired tokens.
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// Principal is the authenticated user of a request.
+type Principal struct {
+	ID    string
+	Roles []string
+	// Claims carries application-specific values alongside the identity,
+	// such as a token version used to revoke refresh tokens.
+	Claims map[string]interface{}
+}
+
+// HasRole reports whether the principal has any of the given roles.
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, have := range p.Roles {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Authenticator identifies the principal of a request.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying p.
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored by Middleware.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// UserID returns the ID of the authenticated principal, or "" for anonymous
+// requests. It can be passed to middleware.KeyByUser.
+func UserID(ctx context.Context) string {
+	if p, ok := FromContext(ctx); ok {
+		return p.ID
+	}
+	return ""
+}
+
+// Middleware authenticates each request with the first authenticator that
+// finds credentials and stores the principal in the request context.
+// Requests without credentials continue anonymously; requests with invalid
+// credentials are rejected with 401.
+func Middleware(authenticators ...Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, authenticator := range authenticators {
+				p, err := authenticator.Authenticate(r)
+				if errors.Is(err, ErrNoCredentials) {
+					continue
+				}
+				if err != nil {
+					unauthorized(w, "invalid credentials")
+					return
+				}
+
+				r = r.WithContext(NewContext(r.Context(), p))
+				break
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireAuth rejects anonymous requests with 401. Use it on a mux router
+// or subrouter:
+//
+//	api.Use(auth.RequireAuth)
+func RequireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := FromContext(r.Context()); !ok {
+			unauthorized(w, "authentication required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireRole rejects anonymous requests with 401 and principals that have
+// none of the given roles with 403.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := FromContext(r.Context())
+			if !ok {
+				unauthorized(w, "authentication required")
+				return
+			}
+			if !p.HasRole(roles...) {
+				forbidden(w)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	problem.Write(w, problem.New(http.StatusUnauthorized, message))
+}
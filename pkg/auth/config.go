@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Config holds the auth section of config.yaml.
+type Config struct {
+	JWT     JWTConfig
+	Session SessionConfig
+}
+
+// JWTConfig configures JWT.
+type JWTConfig struct {
+	Enabled    bool
+	Issuer     string
+	AccessTTL  time.Duration `mapstructure:"access_ttl"`
+	RefreshTTL time.Duration `mapstructure:"refresh_ttl"`
+	// SigningKey is the ID of the key new tokens are signed with. The
+	// other keys only verify tokens, so a key can be rotated out by adding
+	// its successor, switching SigningKey and removing the old key once its
+	// tokens have expired.
+	SigningKey string `mapstructure:"signing_key"`
+	Keys       []KeyConfig
+}
+
+// SessionConfig configures Sessions.
+type SessionConfig struct {
+	Enabled    bool
+	CookieName string        `mapstructure:"cookie_name"`
+	MaxAge     time.Duration `mapstructure:"max_age"`
+	Secure     bool
+	SameSite   string `mapstructure:"same_site"`
+	// Keys sign session cookies; the first key signs new cookies and all of
+	// them verify.
+	Keys []KeyConfig
+}
+
+// KeyConfig describes a signing key. HS256 keys take their secret, of at
+// least MinSecretLength bytes, from Secret or from the environment variable
+// named by SecretEnv; RS256 keys
+// are read from PEM files, and a key without a private key file can only
+// verify.
+type KeyConfig struct {
+	ID             string
+	Algorithm      string
+	Secret         string
+	SecretEnv      string `mapstructure:"secret_env"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	PublicKeyFile  string `mapstructure:"public_key_file"`
+}
+
+// LoadConfig reads the auth section of v.
+func LoadConfig(v *viper.Viper) (Config, error) {
+	cfg := Config{
+		JWT: JWTConfig{
+			Enabled:    v.GetBool("auth.jwt.enabled"),
+			Issuer:     v.GetString("auth.jwt.issuer"),
+			AccessTTL:  v.GetDuration("auth.jwt.access_ttl"),
+			RefreshTTL: v.GetDuration("auth.jwt.refresh_ttl"),
+			SigningKey: v.GetString("auth.jwt.signing_key"),
+		},
+		Session: SessionConfig{
+			Enabled:    v.GetBool("auth.session.enabled"),
+			CookieName: v.GetString("auth.session.cookie_name"),
+			MaxAge:     v.GetDuration("auth.session.max_age"),
+			Secure:     v.GetBool("auth.session.secure"),
+			SameSite:   v.GetString("auth.session.same_site"),
+		},
+	}
+
+	if err := v.UnmarshalKey("auth.jwt.keys", &cfg.JWT.Keys); err != nil {
+		return cfg, fmt.Errorf("failed to read auth.jwt.keys: %w", err)
+	}
+	if err := v.UnmarshalKey("auth.session.keys", &cfg.Session.Keys); err != nil {
+		return cfg, fmt.Errorf("failed to read auth.session.keys: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// FromConfig builds the authenticators enabled in the auth section of v.
+// Disabled authenticators are returned as nil.
+func FromConfig(v *viper.Viper) (*JWT, *Sessions, error) {
+	cfg, err := LoadConfig(v)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tokens *JWT
+	if cfg.JWT.Enabled {
+		if tokens, err = NewJWT(cfg.JWT); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var sessions *Sessions
+	if cfg.Session.Enabled {
+		if sessions, err = NewSessions(cfg.Session); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return tokens, sessions, nil
+}
+
+// MinSecretLength is the length in bytes below which HS256 and session
+// secrets are rejected, as shorter ones can be brute-forced from a single
+// signed token or cookie.
+const MinSecretLength = 32
+
+func (k KeyConfig) secret() ([]byte, error) {
+	secret := k.Secret
+	if k.SecretEnv != "" {
+		secret = os.Getenv(k.SecretEnv)
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("key '%s' has no secret", k.ID)
+	}
+	if len(secret) < MinSecretLength {
+		return nil, fmt.Errorf("key '%s' has a %d-byte secret; secrets must be at least %d bytes", k.ID, len(secret), MinSecretLength)
+	}
+	return []byte(secret), nil
+}
+
+func (k KeyConfig) rsaKeys() (*rsa.PrivateKey, *rsa.PublicKey, error) {
+	var private *rsa.PrivateKey
+	if k.PrivateKeyFile != "" {
+		block, err := readPEM(k.PrivateKeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse private key %s: %w", k.PrivateKeyFile, err)
+		}
+
+		var ok bool
+		if private, ok = parsed.(*rsa.PrivateKey); !ok {
+			return nil, nil, fmt.Errorf("private key %s is not an RSA key", k.PrivateKeyFile)
+		}
+	}
+
+	switch {
+	case k.PublicKeyFile != "":
+		block, err := readPEM(k.PublicKeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse public key %s: %w", k.PublicKeyFile, err)
+		}
+
+		public, ok := parsed.(*rsa.PublicKey)
+		if !ok {
+			return nil, nil, fmt.Errorf("public key %s is not an RSA key", k.PublicKeyFile)
+		}
+		return private, public, nil
+	case private != nil:
+		return private, &private.PublicKey, nil
+	default:
+		return nil, nil, fmt.Errorf("key '%s' needs a private_key_file or public_key_file", k.ID)
+	}
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key file %s is not PEM encoded", path)
+	}
+	return block, nil
+}
+
+func parseSameSite(value string) http.SameSite {
+	switch strings.ToLower(value) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
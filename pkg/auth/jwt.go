@@ -0,0 +1,285 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	accessToken  = "access"
+	refreshToken = "refresh"
+)
+
+// JWT issues and verifies HS256 and RS256 signed JSON Web Tokens. It
+// authenticates requests carrying an access token in an
+// "Authorization: Bearer" header.
+type JWT struct {
+	issuer     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	signingKey *jwtKey
+	keys       map[string]*jwtKey
+}
+
+type jwtKey struct {
+	id        string
+	algorithm string
+	secret    []byte
+	private   *rsa.PrivateKey
+	public    *rsa.PublicKey
+}
+
+// TokenPair is the response of a login or refresh.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+func NewJWT(cfg JWTConfig) (*JWT, error) {
+	j := &JWT{
+		issuer:     cfg.Issuer,
+		accessTTL:  cfg.AccessTTL,
+		refreshTTL: cfg.RefreshTTL,
+		keys:       make(map[string]*jwtKey),
+	}
+	if j.accessTTL <= 0 {
+		j.accessTTL = 15 * time.Minute
+	}
+	if j.refreshTTL <= 0 {
+		j.refreshTTL = 30 * 24 * time.Hour
+	}
+
+	for _, keyConfig := range cfg.Keys {
+		key := &jwtKey{id: keyConfig.ID, algorithm: strings.ToUpper(keyConfig.Algorithm)}
+
+		var err error
+		switch key.algorithm {
+		case "HS256":
+			key.secret, err = keyConfig.secret()
+		case "RS256":
+			key.private, key.public, err = keyConfig.rsaKeys()
+		default:
+			err = fmt.Errorf("key '%s' has unsupported algorithm '%s'", keyConfig.ID, keyConfig.Algorithm)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JWT key: %w", err)
+		}
+
+		j.keys[key.id] = key
+	}
+
+	signingID := cfg.SigningKey
+	if signingID == "" && len(cfg.Keys) == 1 {
+		signingID = cfg.Keys[0].ID
+	}
+	key, ok := j.keys[signingID]
+	if !ok {
+		return nil, fmt.Errorf("JWT signing key '%s' is not configured", signingID)
+	}
+	if key.algorithm == "RS256" && key.private == nil {
+		return nil, fmt.Errorf("JWT signing key '%s' has no private key", signingID)
+	}
+	j.signingKey = key
+
+	return j, nil
+}
+
+// IssueTokens returns a new access and refresh token for p.
+func (j *JWT) IssueTokens(p *Principal) (*TokenPair, error) {
+	access, err := j.issue(p, accessToken, j.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, err := j.issue(p, refreshToken, j.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(j.accessTTL.Seconds()),
+	}, nil
+}
+
+// VerifyAccessToken returns the principal of a valid access token.
+func (j *JWT) VerifyAccessToken(token string) (*Principal, error) {
+	return j.verify(token, accessToken)
+}
+
+// VerifyRefreshToken returns the principal of a valid refresh token.
+func (j *JWT) VerifyRefreshToken(token string) (*Principal, error) {
+	return j.verify(token, refreshToken)
+}
+
+// Authenticate implements Authenticator.
+func (j *JWT) Authenticate(r *http.Request) (*Principal, error) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrNoCredentials
+	}
+	return j.VerifyAccessToken(strings.TrimSpace(token))
+}
+
+func (j *JWT) issue(p *Principal, tokenType string, ttl time.Duration) (string, error) {
+	now := time.Now()
+
+	claims := make(map[string]interface{}, len(p.Claims)+8)
+	for name, value := range p.Claims {
+		claims[name] = value
+	}
+	claims["sub"] = p.ID
+	claims["typ"] = tokenType
+	claims["iat"] = now.Unix()
+	claims["nbf"] = now.Unix()
+	claims["exp"] = now.Add(ttl).Unix()
+	claims["jti"] = newID()
+	if len(p.Roles) > 0 {
+		claims["roles"] = p.Roles
+	}
+	if j.issuer != "" {
+		claims["iss"] = j.issuer
+	}
+
+	header, err := json.Marshal(jwtHeader{Algorithm: j.signingKey.algorithm, Type: "JWT", KeyID: j.signingKey.id})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode claims: %w", err)
+	}
+
+	signed := encodeSegment(header) + "." + encodeSegment(payload)
+	signature, err := j.signingKey.sign([]byte(signed))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return signed + "." + encodeSegment(signature), nil
+}
+
+func (j *JWT) verify(token, tokenType string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	key, ok := j.keys[header.KeyID]
+	if !ok && header.KeyID == "" && len(j.keys) == 1 {
+		key, ok = j.signingKey, true
+	}
+	// The algorithm is fixed by the key, never taken from the token, so an
+	// RS256 public key cannot be replayed as an HS256 secret.
+	if !ok || header.Algorithm != key.algorithm {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !key.verify([]byte(parts[0]+"."+parts[1]), signature) {
+		return nil, ErrInvalidToken
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	now := float64(time.Now().Unix())
+	if exp, ok := claims["exp"].(float64); !ok || now >= exp {
+		return nil, fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now < nbf {
+		return nil, fmt.Errorf("%w: not yet valid", ErrInvalidToken)
+	}
+	if typ, _ := claims["typ"].(string); typ != tokenType {
+		return nil, fmt.Errorf("%w: expected %s token", ErrInvalidToken, tokenType)
+	}
+	if j.issuer != "" && claims["iss"] != j.issuer {
+		return nil, fmt.Errorf("%w: wrong issuer", ErrInvalidToken)
+	}
+
+	p := &Principal{Claims: make(map[string]interface{})}
+	p.ID, _ = claims["sub"].(string)
+	if roles, ok := claims["roles"].([]interface{}); ok {
+		for _, role := range roles {
+			if name, ok := role.(string); ok {
+				p.Roles = append(p.Roles, name)
+			}
+		}
+	}
+	for name, value := range claims {
+		switch name {
+		case "sub", "roles", "typ", "iat", "nbf", "exp", "jti", "iss":
+		default:
+			p.Claims[name] = value
+		}
+	}
+
+	return p, nil
+}
+
+func (k *jwtKey) sign(data []byte) ([]byte, error) {
+	if k.algorithm == "HS256" {
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(data)
+		return mac.Sum(nil), nil
+	}
+
+	digest := sha256.Sum256(data)
+	return rsa.SignPKCS1v15(rand.Reader, k.private, crypto.SHA256, digest[:])
+}
+
+func (k *jwtKey) verify(data, signature []byte) bool {
+	if k.algorithm == "HS256" {
+		expected, _ := k.sign(data)
+		return hmac.Equal(expected, signature)
+	}
+
+	digest := sha256.Sum256(data)
+	return rsa.VerifyPKCS1v15(k.public, crypto.SHA256, digest[:], signature) == nil
+}
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
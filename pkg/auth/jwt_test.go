@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// newTestJWT returns a JWT signing with an RS256 key that also accepts
+// tokens of an HS256 key, and the PEM encoding of the RSA public key.
+func newTestJWT(t *testing.T) (*JWT, []byte) {
+	t.Helper()
+
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	dir := t.TempDir()
+	privateFile := filepath.Join(dir, "jwt.pem")
+	publicFile := filepath.Join(dir, "jwt.pub")
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)})
+	if err := os.WriteFile(privateFile, privatePEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicFile, publicPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := NewJWT(JWTConfig{
+		Issuer:     "test",
+		SigningKey: "rsa",
+		Keys: []KeyConfig{
+			{ID: "rsa", Algorithm: "RS256", PrivateKeyFile: privateFile, PublicKeyFile: publicFile},
+			{ID: "hmac", Algorithm: "HS256", Secret: testSecret},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return j, publicPEM
+}
+
+// forge builds a token from header and claims, signed by key.
+func forge(t *testing.T, header jwtHeader, claims map[string]interface{}, key *jwtKey) string {
+	t.Helper()
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed := encodeSegment(headerJSON) + "." + encodeSegment(claimsJSON)
+	if key == nil {
+		return signed + "."
+	}
+	signature, err := key.sign([]byte(signed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + encodeSegment(signature)
+}
+
+func validClaims(changes map[string]interface{}) map[string]interface{} {
+	now := time.Now()
+	claims := map[string]interface{}{
+		"sub":   "42",
+		"typ":   accessToken,
+		"iss":   "test",
+		"iat":   now.Unix(),
+		"nbf":   now.Unix(),
+		"exp":   now.Add(time.Minute).Unix(),
+		"roles": []string{"admin"},
+	}
+	for name, value := range changes {
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+	}
+	return claims
+}
+
+func TestJWTVerifyAccessToken(t *testing.T) {
+	j, publicPEM := newTestJWT(t)
+	rsaKey, hmacKey := j.keys["rsa"], j.keys["hmac"]
+	// An attacker knowing the RSA public key signs an HS256 token with it.
+	confused := &jwtKey{id: "rsa", algorithm: "HS256", secret: publicPEM}
+
+	rs256 := jwtHeader{Algorithm: "RS256", Type: "JWT", KeyID: "rsa"}
+	hs256 := jwtHeader{Algorithm: "HS256", Type: "JWT", KeyID: "hmac"}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "RS256", token: forge(t, rs256, validClaims(nil), rsaKey)},
+		{name: "HS256 verification key", token: forge(t, hs256, validClaims(nil), hmacKey)},
+		{name: "expired", token: forge(t, rs256, validClaims(map[string]interface{}{"exp": time.Now().Add(-time.Second).Unix()}), rsaKey), wantErr: true},
+		{name: "no expiry", token: forge(t, rs256, validClaims(map[string]interface{}{"exp": nil}), rsaKey), wantErr: true},
+		{name: "not yet valid", token: forge(t, rs256, validClaims(map[string]interface{}{"nbf": time.Now().Add(time.Hour).Unix()}), rsaKey), wantErr: true},
+		{name: "refresh token", token: forge(t, rs256, validClaims(map[string]interface{}{"typ": refreshToken}), rsaKey), wantErr: true},
+		{name: "wrong issuer", token: forge(t, rs256, validClaims(map[string]interface{}{"iss": "other"}), rsaKey), wantErr: true},
+		{name: "public key as HS256 secret", token: forge(t, jwtHeader{Algorithm: "HS256", KeyID: "rsa"}, validClaims(nil), confused), wantErr: true},
+		{name: "alg none", token: forge(t, jwtHeader{Algorithm: "none", KeyID: "rsa"}, validClaims(nil), nil), wantErr: true},
+		{name: "algorithm not matching key", token: forge(t, jwtHeader{Algorithm: "RS256", KeyID: "hmac"}, validClaims(nil), rsaKey), wantErr: true},
+		{name: "unknown key", token: forge(t, jwtHeader{Algorithm: "RS256", KeyID: "gone"}, validClaims(nil), rsaKey), wantErr: true},
+		{name: "signed by another key", token: forge(t, rs256, validClaims(nil), &jwtKey{algorithm: "HS256", secret: []byte(testSecret)}), wantErr: true},
+		{name: "malformed", token: "not.a-token", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := j.VerifyAccessToken(tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("got principal %v and error %v, want ErrInvalidToken", p, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.ID != "42" || !p.HasRole("admin") {
+				t.Errorf("got principal %+v", p)
+			}
+		})
+	}
+}
+
+func TestJWTTamperedPayload(t *testing.T) {
+	j, _ := newTestJWT(t)
+
+	pair, err := j.IssueTokens(&Principal{ID: "42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.VerifyAccessToken(pair.AccessToken); err != nil {
+		t.Fatalf("issued access token rejected: %v", err)
+	}
+	if _, err := j.VerifyRefreshToken(pair.AccessToken); err == nil {
+		t.Error("access token accepted as refresh token")
+	}
+
+	parts := strings.Split(pair.AccessToken, ".")
+	claims := validClaims(map[string]interface{}{"sub": "1"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := parts[0] + "." + encodeSegment(payload) + "." + parts[2]
+	if _, err := j.VerifyAccessToken(tampered); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("tampered token: got error %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestNewJWTRejectsShortSecrets(t *testing.T) {
+	_, err := NewJWT(JWTConfig{Keys: []KeyConfig{{ID: "k", Algorithm: "HS256", Secret: "too short"}}})
+	if err == nil || !strings.Contains(err.Error(), "at least 32 bytes") {
+		t.Fatalf("got error %v, want a secret length error", err)
+	}
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// Permissions holds the permissions granted to roles and those required by
+// routes. Each App has its own, as App.Permissions, which grants the
+// permissions listed under auth.roles in config.yaml.
+type Permissions struct {
+	grants map[string][]string
+	routes map[*mux.Route][]string
+	mutex  sync.RWMutex
+}
+
+// NewPermissions returns an empty permission table.
+func NewPermissions() *Permissions {
+	return &Permissions{
+		grants: make(map[string][]string),
+		routes: make(map[*mux.Route][]string),
+	}
+}
+
+// Grant grants permissions to every principal with role. "*" grants every
+// permission and a trailing ".*", as in "posts.*", every permission with that
+// prefix.
+func (ps *Permissions) Grant(role string, permissions ...string) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	ps.grants[role] = append(ps.grants[role], permissions...)
+}
+
+// Allows reports whether any of the principal's roles has been granted
+// permission. Anonymous principals, nil, have no permissions.
+func (ps *Permissions) Allows(p *Principal, permission string) bool {
+	if p == nil {
+		return false
+	}
+
+	ps.mutex.RLock()
+	defer ps.mutex.RUnlock()
+
+	for _, role := range p.Roles {
+		for _, granted := range ps.grants[role] {
+			if granted == "*" || granted == permission ||
+				(strings.HasSuffix(granted, ".*") && strings.HasPrefix(permission, strings.TrimSuffix(granted, "*"))) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Route annotates route as requiring all of permissions, which Enforce
+// checks. It returns route for chaining:
+//
+//	app.Permissions.Route(api.HandleFunc("/posts/{id}", c.Delete).Methods("DELETE"), "posts.delete")
+func (ps *Permissions) Route(route *mux.Route, permissions ...string) *mux.Route {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+	ps.routes[route] = append(ps.routes[route], permissions...)
+	return route
+}
+
+// Enforce checks the permissions annotated on the matched route with Route.
+// It must be installed with Router.Use so the route is known; the framework
+// installs App.Permissions.Enforce on App.Router.
+func (ps *Permissions) Enforce(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var required []string
+		if route := mux.CurrentRoute(r); route != nil {
+			ps.mutex.RLock()
+			required = ps.routes[route]
+			ps.mutex.RUnlock()
+		}
+
+		if len(required) > 0 && !ps.check(w, r, required) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Require rejects requests whose principal lacks any of permissions, with
+// 401 for anonymous requests and 403 otherwise.
+func (ps *Permissions) Require(permissions ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !ps.check(w, r, permissions) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (ps *Permissions) check(w http.ResponseWriter, r *http.Request, permissions []string) bool {
+	p, ok := FromContext(r.Context())
+	if !ok {
+		unauthorized(w, "authentication required")
+		return false
+	}
+
+	for _, permission := range permissions {
+		if !ps.Allows(p, permission) {
+			forbidden(w)
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ThreadBolt/threadbolt/pkg/problem"
+)
+
+var (
+	// ErrForbidden is returned when a policy denies an action.
+	ErrForbidden = errors.New("forbidden")
+	// ErrUnauthenticated is returned when an anonymous request is denied an
+	// action that an authenticated one might be allowed.
+	ErrUnauthenticated = errors.New("authentication required")
+)
+
+var (
+	policies      = make(map[reflect.Type]reflect.Value)
+	policiesMutex sync.RWMutex
+)
+
+var principalType = reflect.TypeOf((*Principal)(nil))
+
+// beforePolicy is implemented by policies that decide some actions up front,
+// for example to let admins do everything.
+type beforePolicy interface {
+	Before(p *Principal, action string) (allowed bool, decided bool)
+}
+
+// RegisterPolicy registers policy for resources of model's type. An action
+// such as "update" is decided by the policy's CanUpdate method, which takes
+// the principal, nil for anonymous requests, and the resource:
+//
+//	func (PostPolicy) CanUpdate(p *auth.Principal, post *models.Post) bool
+//
+// RegisterPolicy panics if policy has no such methods for model's type.
+func RegisterPolicy(model interface{}, policy interface{}) {
+	modelType := resourceType(reflect.TypeOf(model))
+	policyValue := reflect.ValueOf(policy)
+
+	found := false
+	for i := 0; i < policyValue.NumMethod(); i++ {
+		method := policyValue.Type().Method(i)
+		if strings.HasPrefix(method.Name, "Can") && isPolicyMethod(method.Type, modelType) {
+			found = true
+		}
+	}
+	if !found {
+		panic(fmt.Sprintf("auth: policy %T has no Can methods for %s", policy, modelType))
+	}
+
+	policiesMutex.Lock()
+	defer policiesMutex.Unlock()
+	policies[modelType] = policyValue
+}
+
+// Authorize checks whether the principal of ctx may perform action on
+// resource. Resources without a registered policy are allowed; a registered
+// policy without a method for action denies it.
+func Authorize(ctx context.Context, action string, resource interface{}) error {
+	p, _ := FromContext(ctx)
+
+	allowed, err := decide(p, action, resource)
+	if err != nil {
+		slog.WarnContext(ctx, "authorization denied", "action", action, "error", err)
+	}
+	if allowed {
+		return nil
+	}
+	if p == nil {
+		return ErrUnauthenticated
+	}
+	return ErrForbidden
+}
+
+// Authorized is Authorize for handlers: when the action is denied it writes
+// a JSON 401 or 403 response and returns false.
+//
+//	if !auth.Authorized(w, r, "update", post) {
+//		return
+//	}
+func Authorized(w http.ResponseWriter, r *http.Request, action string, resource interface{}) bool {
+	switch err := Authorize(r.Context(), action, resource); {
+	case err == nil:
+		return true
+	case errors.Is(err, ErrUnauthenticated):
+		unauthorized(w, "authentication required")
+	default:
+		forbidden(w)
+	}
+	return false
+}
+
+func decide(p *Principal, action string, resource interface{}) (bool, error) {
+	value := reflect.ValueOf(resource)
+	if !value.IsValid() {
+		return false, fmt.Errorf("nil resource")
+	}
+	modelType := resourceType(value.Type())
+
+	policiesMutex.RLock()
+	policy, ok := policies[modelType]
+	policiesMutex.RUnlock()
+	if !ok {
+		return true, nil
+	}
+
+	if before, ok := policy.Interface().(beforePolicy); ok {
+		if allowed, decided := before.Before(p, action); decided {
+			return allowed, nil
+		}
+	}
+
+	method := policy.MethodByName("Can" + actionName(action))
+	if !method.IsValid() || !isPolicyMethod(method.Type(), modelType) {
+		return false, fmt.Errorf("policy %s has no method Can%s", policy.Type(), actionName(action))
+	}
+
+	arg, err := resourceArg(value, method.Type().In(1))
+	if err != nil {
+		return false, err
+	}
+
+	principal := reflect.Zero(principalType)
+	if p != nil {
+		principal = reflect.ValueOf(p)
+	}
+	return method.Call([]reflect.Value{principal, arg})[0].Bool(), nil
+}
+
+// isPolicyMethod reports whether methodType, without its receiver, is
+// func(*Principal, T) bool for T being modelType or a pointer to it.
+func isPolicyMethod(methodType reflect.Type, modelType reflect.Type) bool {
+	in := 0
+	if methodType.NumIn() == 3 {
+		// Method from a type's method set still has its receiver.
+		in = 1
+	}
+	if methodType.NumIn()-in != 2 || methodType.NumOut() != 1 || methodType.Out(0).Kind() != reflect.Bool {
+		return false
+	}
+	return methodType.In(in) == principalType && resourceType(methodType.In(in+1)) == modelType
+}
+
+// resourceArg converts value to the policy method's parameter type, taking
+// the address of or dereferencing the resource as needed.
+func resourceArg(value reflect.Value, paramType reflect.Type) (reflect.Value, error) {
+	switch {
+	case value.Type() == paramType:
+		return value, nil
+	case value.Kind() == reflect.Ptr && value.Type().Elem() == paramType:
+		if value.IsNil() {
+			return reflect.Zero(paramType), nil
+		}
+		return value.Elem(), nil
+	case paramType.Kind() == reflect.Ptr && paramType.Elem() == value.Type():
+		ptr := reflect.New(value.Type())
+		ptr.Elem().Set(value)
+		return ptr, nil
+	default:
+		return reflect.Value{}, fmt.Errorf("cannot pass %s to policy as %s", value.Type(), paramType)
+	}
+}
+
+func resourceType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// actionName converts actions such as "update", "view_any" or "viewAny" to
+// the method suffix "Update" or "ViewAny".
+func actionName(action string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range action {
+		if r == '_' || r == '-' || r == ' ' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func forbidden(w http.ResponseWriter) {
+	problem.Write(w, problem.New(http.StatusForbidden, "you are not allowed to perform this action"))
+}
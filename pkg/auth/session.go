@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Sessions keeps the principal in an HMAC-signed cookie, so no server-side
+// session store is needed.
+type Sessions struct {
+	cookieName string
+	maxAge     time.Duration
+	secure     bool
+	sameSite   http.SameSite
+	keys       []sessionKey
+}
+
+type sessionKey struct {
+	id     string
+	secret []byte
+}
+
+type sessionPayload struct {
+	Subject   string                 `json:"sub"`
+	Roles     []string               `json:"roles,omitempty"`
+	Claims    map[string]interface{} `json:"claims,omitempty"`
+	ExpiresAt int64                  `json:"exp"`
+}
+
+func NewSessions(cfg SessionConfig) (*Sessions, error) {
+	s := &Sessions{
+		cookieName: cfg.CookieName,
+		maxAge:     cfg.MaxAge,
+		secure:     cfg.Secure,
+		sameSite:   parseSameSite(cfg.SameSite),
+	}
+	if s.cookieName == "" {
+		s.cookieName = "threadbolt_session"
+	}
+	if s.maxAge <= 0 {
+		s.maxAge = 24 * time.Hour
+	}
+
+	for _, keyConfig := range cfg.Keys {
+		secret, err := keyConfig.secret()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load session key: %w", err)
+		}
+		s.keys = append(s.keys, sessionKey{id: keyConfig.ID, secret: secret})
+	}
+	if len(s.keys) == 0 {
+		return nil, fmt.Errorf("no session keys configured")
+	}
+
+	return s, nil
+}
+
+// Login sets a session cookie for p.
+func (s *Sessions) Login(w http.ResponseWriter, p *Principal) error {
+	expires := time.Now().Add(s.maxAge)
+
+	payload, err := json.Marshal(sessionPayload{
+		Subject:   p.ID,
+		Roles:     p.Roles,
+		Claims:    p.Claims,
+		ExpiresAt: expires.Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	key := s.keys[0]
+	value := key.id + "." + encodeSegment(payload)
+	value += "." + encodeSegment(key.sign(value))
+
+	http.SetCookie(w, s.cookie(value, expires))
+	return nil
+}
+
+// Logout clears the session cookie.
+func (s *Sessions) Logout(w http.ResponseWriter) {
+	cookie := s.cookie("", time.Unix(0, 0))
+	cookie.MaxAge = -1
+	http.SetCookie(w, cookie)
+}
+
+// Authenticate implements Authenticator. An expired session counts as no
+// session at all.
+func (s *Sessions) Authenticate(r *http.Request) (*Principal, error) {
+	cookie, err := r.Cookie(s.cookieName)
+	if err != nil || cookie.Value == "" {
+		return nil, ErrNoCredentials
+	}
+
+	parts := strings.Split(cookie.Value, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	valid := false
+	for _, key := range s.keys {
+		if key.id == parts[0] && hmac.Equal(key.sign(parts[0]+"."+parts[1]), signature) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, ErrInvalidToken
+	}
+
+	var payload sessionPayload
+	if err := decodeSegment(parts[1], &payload); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().Unix() >= payload.ExpiresAt {
+		return nil, ErrNoCredentials
+	}
+
+	return &Principal{ID: payload.Subject, Roles: payload.Roles, Claims: payload.Claims}, nil
+}
+
+func (s *Sessions) cookie(value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     s.cookieName,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		Secure:   s.secure,
+		HttpOnly: true,
+		SameSite: s.sameSite,
+	}
+}
+
+func (k sessionKey) sign(value string) []byte {
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
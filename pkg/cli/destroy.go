@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ThreadBolt/threadbolt/pkg/generator"
+	"github.com/spf13/cobra"
+)
+
+var destroyCmd = &cobra.Command{
+	Use:     "destroy",
+	Short:   "Remove generated ThreadBolt components",
+	Aliases: []string{"d"},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupLogging(cmd); err != nil {
+			return err
+		}
+		return applyWriteFlags(cmd)
+	},
+}
+
+var destroyControllerCmd = &cobra.Command{
+	Use:   "controller [name]",
+	Short: "Remove a controller and its routes",
+	Long: `Removes the routes of a controller from SetupRoutes in routes/routes.go, along
+with the controllers import if nothing else uses it, then deletes the
+controller and its generated tests. The model is kept.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		controllerName := strings.Title(args[0])
+
+		files, err := generator.DestroyController(controllerName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error destroying controller: %v\n", err)
+			os.Exit(1)
+		}
+
+		if dryRun {
+			generated("")
+			return
+		}
+		fmt.Printf("✅ Destroyed controller: %s\n", controllerName)
+		for _, file := range files {
+			fmt.Printf("   %s\n", file)
+		}
+	},
+}
+
+func init() {
+	destroyCmd.PersistentFlags().Bool("dry-run", false, "List the files that would change without changing them")
+	destroyCmd.PersistentFlags().Bool("diff", false, "Show a unified diff of the changes to existing files")
+	destroyCmd.AddCommand(destroyControllerCmd)
+}
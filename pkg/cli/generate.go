@@ -1,53 +1,254 @@
 package cli
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/ThreadBolt/threadbolt/pkg/generator"
+	"github.com/ThreadBolt/threadbolt/pkg/orm"
+	"gorm.io/gorm/schema"
 )
 
 var generateCmd = &cobra.Command{
 	Use:   "generate",
 	Short: "Generate ThreadBolt components",
+	Long: `Generates ThreadBolt components. A generated file that already exists with
+different content is only overwritten after asking, or with --force; --skip
+keeps it instead. --dry-run lists the files that would change without writing
+them and --diff shows the changes to existing files.`,
 	Aliases: []string{"gen", "g"},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupLogging(cmd); err != nil {
+			return err
+		}
+		return applyWriteFlags(cmd)
+	},
 }
 
 var generateModelCmd = &cobra.Command{
-	Use:   "model [name]",
+	Use:   "model [name] [field:type[:modifier]...]",
 	Short: "Generate a new model",
-	Args:  cobra.ExactArgs(1),
+	Long: `Generates a model, its repository and request DTOs, then a migration creating
+its table. Fields are given as name:type[:modifier...]:
+
+  threadbolt generate model User name:string email:string:unique age:int team:references
+
+Types are string{size}, text, uuid, int, bigint, uint, float,
+decimal{precision,scale}, bool, time, date and references{Model}. Modifiers
+are unique, index, null and default=value. Quote arguments with braces
+containing commas, such as 'price:decimal{10,2}'.`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		modelName := strings.Title(args[0])
-		
-		if err := generator.GenerateModel(modelName); err != nil {
+		skipMigration, _ := cmd.Flags().GetBool("skip-migration")
+
+		fields, err := generator.ParseFields(args[1:])
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error generating model: %v\n", err)
 			os.Exit(1)
 		}
-		
-		fmt.Printf("✅ Generated model: %s\n", modelName)
+
+		generateModel(modelName, fields, skipMigration)
 	},
 }
 
+// generateModel generates a model and, inside a project, the migration
+// creating its table, exiting if the model cannot be generated.
+func generateModel(modelName string, fields []generator.Field, skipMigration bool) {
+	if err := generator.GenerateModel(modelName, fields); err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating model: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !generated("Generated model: %s", modelName) || skipMigration || !inProject() {
+		return
+	}
+	generateModelMigration(modelName)
+}
+
+// generateModelMigration generates the migration creating a new model's
+// table. Failing to do so, such as when the project does not compile, only
+// warns, keeping the generated files, and prints the command to retry.
+func generateModelMigration(modelName string) {
+	migration := "create_" + schema.NamingStrategy{}.TableName(modelName)
+	files, err := generateAutoMigration(migration)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to generate migration: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Run 'threadbolt generate migration %s --auto' once the error above is fixed.\n", migration)
+		return
+	}
+	for _, file := range files {
+		fmt.Printf("✅ Generated migration: %s\n", file)
+	}
+}
+
 var generateControllerCmd = &cobra.Command{
 	Use:   "controller [name]",
 	Short: "Generate a new controller",
-	Args:  cobra.ExactArgs(1),
+	Long: `Generates a CRUD controller for the model of the same name and registers its
+routes in SetupRoutes in routes/routes.go. If the model does not exist yet, it
+is generated first with --model, or after asking on a terminal.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		controllerName := strings.Title(args[0])
-		
-		if err := generator.GenerateController(controllerName); err != nil {
+		withModel, _ := cmd.Flags().GetBool("model")
+
+		err := generator.GenerateController(controllerName)
+		if errors.Is(err, generator.ErrModelNotFound) &&
+			(withModel || confirm(fmt.Sprintf("Model %s does not exist. Generate it?", controllerName))) {
+			generateModel(controllerName, nil, false)
+			err = generator.GenerateController(controllerName)
+		}
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error generating controller: %v\n", err)
+			if errors.Is(err, generator.ErrModelNotFound) {
+				fmt.Fprintf(os.Stderr, "Run 'threadbolt generate model %s' first, or pass --model.\n", controllerName)
+			}
+			os.Exit(1)
+		}
+
+		generated("Generated controller: %s", controllerName)
+	},
+}
+
+var generateMigrationCmd = &cobra.Command{
+	Use:   "migration [name]",
+	Short: "Generate timestamped up/down SQL migration files",
+	Long: `Generates an empty pair of up/down SQL files in migrations/. With --auto the
+registered models are compared against the live database schema and the
+statements needed to reconcile them are written for the configured driver.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		auto, _ := cmd.Flags().GetBool("auto")
+
+		if auto && !inProject() {
+			fmt.Fprintln(os.Stderr, "Error generating migration: --auto must be run inside a ThreadBolt project")
 			os.Exit(1)
 		}
-		
-		fmt.Printf("✅ Generated controller: %s\n", controllerName)
+
+		var files []string
+		var err error
+		if auto {
+			files, err = generateAutoMigration(args[0])
+		} else {
+			files, err = generator.GenerateMigration(args[0], nil, nil)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating migration: %v\n", err)
+			os.Exit(1)
+		}
+
+		if dryRun {
+			generated("")
+			return
+		}
+		if len(files) == 0 {
+			fmt.Println("Schema is up to date, no migration generated")
+			return
+		}
+		for _, file := range files {
+			fmt.Printf("✅ Generated migration: %s\n", file)
+		}
+	},
+}
+
+// generateAutoMigration writes a migration with the statements that reconcile
+// the database schema with the project's registered models, as planned by the
+// project's "migrate plan" command. It writes nothing and returns no files if
+// the schema is up to date.
+func generateAutoMigration(name string) ([]string, error) {
+	output, err := outputInProject("migrate", "plan")
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan migration: %w", err)
+	}
+
+	// The plan is the last line of output, after anything the application
+	// printed while starting.
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	var plan orm.MigrationPlan
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &plan); err != nil {
+		return nil, fmt.Errorf("failed to read migration plan: %w", err)
+	}
+	if len(plan.Up) == 0 {
+		return nil, nil
+	}
+
+	return generator.GenerateMigration(name, plan.Up, plan.Down)
+}
+
+var generateScaffoldCmd = &cobra.Command{
+	Use:   "scaffold [name] [field:type[:modifier]...]",
+	Short: "Generate a model, controller, routes, migration and tests",
+	Long: `Generates a model with its repository and request DTOs, a CRUD controller,
+routes in routes/routes.go, a migration creating its table and table-driven
+tests in tests/. Fields are given as for 'generate model':
+
+  threadbolt generate scaffold Post title:string body:text
+
+If generating a file fails, every file written is restored. If the migration
+cannot be generated, such as when the project does not compile, the files are
+kept and the command to generate it once fixed is printed.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		modelName := strings.Title(args[0])
+		skipMigration, _ := cmd.Flags().GetBool("skip-migration")
+
+		fields, err := generator.ParseFields(args[1:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating scaffold: %v\n", err)
+			os.Exit(1)
+		}
+		if !inProject() {
+			fmt.Fprintln(os.Stderr, "Error generating scaffold: must be run inside a ThreadBolt project")
+			os.Exit(1)
+		}
+
+		if err := generator.GenerateScaffold(modelName, fields); err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating scaffold: %v\n", err)
+			fmt.Fprintln(os.Stderr, "No files were changed.")
+			os.Exit(1)
+		}
+
+		if generated("Generated scaffold: %s", modelName) && !skipMigration {
+			generateModelMigration(modelName)
+		}
+	},
+}
+
+var generateAuthCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Generate a User model and authentication endpoints",
+	Long: `Generates models/user.go, controllers/auth_controller.go and routes/auth.go
+with register, login, refresh, logout and me endpoints, enables JWT and session
+auth in config/config.yaml and writes their secrets to .env.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := generator.GenerateAuth(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating auth: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !generated("Generated auth: models/user.go, controllers/auth_controller.go, routes/auth.go") {
+			return
+		}
+		fmt.Println("Call AuthRoutes(app) from SetupRoutes in routes/routes.go, then run 'threadbolt generate migration create_users --auto'.")
 	},
 }
 
 func init() {
+	addWriteFlags(generateCmd.PersistentFlags())
+	generateControllerCmd.Flags().Bool("model", false, "Generate the model first if it does not exist")
+	generateModelCmd.Flags().Bool("skip-migration", false, "Do not generate a migration for the model")
+	generateScaffoldCmd.Flags().Bool("skip-migration", false, "Do not generate a migration for the model")
+	generateMigrationCmd.Flags().Bool("auto", false, "Diff registered models against the database schema")
+
 	generateCmd.AddCommand(generateModelCmd)
 	generateCmd.AddCommand(generateControllerCmd)
+	generateCmd.AddCommand(generateMigrationCmd)
+	generateCmd.AddCommand(generateScaffoldCmd)
+	generateCmd.AddCommand(generateAuthCmd)
 }
\ No newline at end of file
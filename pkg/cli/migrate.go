@@ -3,26 +3,161 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strconv"
 
-	"github.com/spf13/cobra"
 	"github.com/ThreadBolt/threadbolt/pkg/framework"
+	"github.com/ThreadBolt/threadbolt/pkg/orm"
+	"github.com/spf13/cobra"
 )
 
 var migrateCmd = &cobra.Command{
 	Use:   "migrate",
 	Short: "Run database migrations",
+	Long: `Applies pending SQL migrations and then auto-migrates every model registered
+with orm.RegisterModel. Inside a project the command runs through the project's
+main package so that its models are available.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		commandArgs := []string{"migrate"}
+		if dryRun {
+			commandArgs = append(commandArgs, "--dry-run")
+		}
+
+		if inProject() {
+			exitOnMigrationError(runInProject(commandArgs...))
+		} else {
+			_, err := loadMigrationApp().RunCommand(commandArgs)
+			exitOnMigrationError(err)
+		}
+
+		if !dryRun {
+			fmt.Println("✅ Migrations completed successfully")
+		}
+	},
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply all pending migrations",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		applied, err := loadMigrationApp().Migrator().Up()
+		printMigrations("Applied", applied)
+		exitOnMigrationError(err)
+
+		if len(applied) == 0 {
+			fmt.Println("Database is up to date")
+		}
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down [n]",
+	Short: "Roll back the last n migrations (default 1)",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		n := 1
+		if len(args) == 1 {
+			var err error
+			if n, err = strconv.Atoi(args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid number of migrations %q\n", args[0])
+				os.Exit(1)
+			}
+		}
+
+		rolledBack, err := loadMigrationApp().Migrator().Down(n)
+		printMigrations("Rolled back", rolledBack)
+		exitOnMigrationError(err)
+
+		if len(rolledBack) == 0 {
+			fmt.Println("No migrations to roll back")
+		}
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of each migration",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		statuses, err := loadMigrationApp().Migrator().Status()
+		exitOnMigrationError(err)
+
+		if len(statuses) == 0 {
+			fmt.Println("No migrations found")
+			return
+		}
+
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if status.Modified {
+				state += " (modified)"
+			}
+			if status.Missing {
+				state += " (file missing)"
+			}
+			fmt.Printf("%-16s %-40s %s\n", status.Version, status.Name, state)
+		}
+	},
+}
+
+var migrateRedoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "Roll back and re-apply the last migration",
+	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		app, err := framework.LoadApp()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error loading app: %v\n", err)
-			os.Exit(1)
-		}
-		
-		if err := app.RunMigrations(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error running migrations: %v\n", err)
-			os.Exit(1)
-		}
-		
-		fmt.Println("✅ Migrations completed successfully")
+		migration, err := loadMigrationApp().Migrator().Redo()
+		exitOnMigrationError(err)
+
+		fmt.Printf("✅ Redone: %s_%s\n", migration.Version, migration.Name)
+	},
+}
+
+var migrateToCmd = &cobra.Command{
+	Use:   "to <version>",
+	Short: "Migrate up or down to the given version",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		migrations, err := loadMigrationApp().Migrator().To(args[0])
+		printMigrations("Migrated", migrations)
+		exitOnMigrationError(err)
+
+		fmt.Printf("✅ Database is at version %s\n", args[0])
 	},
-}
\ No newline at end of file
+}
+
+func init() {
+	migrateCmd.Flags().Bool("dry-run", false, "Print the DDL that would be run for registered models without executing it")
+
+	migrateCmd.AddCommand(migrateUpCmd)
+	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateRedoCmd)
+	migrateCmd.AddCommand(migrateToCmd)
+}
+
+func loadMigrationApp() *framework.App {
+	app, err := framework.LoadApp()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading app: %v\n", err)
+		os.Exit(1)
+	}
+	return app
+}
+
+func printMigrations(action string, migrations []orm.Migration) {
+	for _, migration := range migrations {
+		fmt.Printf("%s: %s_%s\n", action, migration.Version, migration.Name)
+	}
+}
+
+func exitOnMigrationError(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running migrations: %v\n", err)
+		os.Exit(1)
+	}
+}
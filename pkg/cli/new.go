@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/ThreadBolt/threadbolt/pkg/generator"
 	"github.com/spf13/cobra"
@@ -11,11 +12,40 @@ import (
 var newCmd = &cobra.Command{
 	Use:   "new [app-name]",
 	Short: "Create a new ThreadBolt application",
-	Args:  cobra.ExactArgs(1),
+	Long: `Creates a new ThreadBolt application from a project template:
+
+  api      a JSON API with a database, models and migrations (the default)
+  web      server-rendered views, static assets and cookie sessions
+  minimal  routes and controllers only, without a database
+
+--template also takes the path of a custom template directory, such as a git
+checkout. Its .tmpl files are rendered and the others copied; variables
+declared in its template.yaml are set with --var name=value or asked for.`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		appName := args[0]
+		templateName, _ := cmd.Flags().GetString("template")
+		vars, _ := cmd.Flags().GetStringArray("var")
+
+		if err := applyWriteFlags(cmd); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating project: %v\n", err)
+			os.Exit(1)
+		}
+
+		opts := generator.ProjectOptions{Template: templateName, Vars: make(map[string]string)}
+		for _, v := range vars {
+			name, value, ok := strings.Cut(v, "=")
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Error creating project: invalid --var %q, expected name=value\n", v)
+				os.Exit(1)
+			}
+			opts.Vars[name] = value
+		}
+		if isTerminal() {
+			opts.Prompt = promptVariable
+		}
 
-		if err := generator.CreateNewProject(appName); err != nil {
+		if err := generator.CreateNewProject(appName, opts); err != nil {
 			fmt.Fprintf(os.Stderr, "Error creating project: %v\n", err)
 			os.Exit(1)
 		}
@@ -27,5 +57,8 @@ var newCmd = &cobra.Command{
 }
 
 func init() {
-	newCmd.Flags().StringP("template", "t", "api", "Project template (api, web, minimal)")
+	newCmd.Flags().Bool("force", false, "Overwrite existing files that differ from the generated ones")
+	newCmd.Flags().Bool("skip", false, "Keep existing files that differ from the generated ones")
+	newCmd.Flags().StringP("template", "t", "api", "Project template (api, web, minimal) or the path of a template directory")
+	newCmd.Flags().StringArray("var", nil, "Set a custom template variable (name=value)")
 }
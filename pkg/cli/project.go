@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"log/slog"
+	"os"
+	"os/exec"
+)
+
+// inProject reports whether the working directory is a ThreadBolt project
+// with its own main package.
+func inProject() bool {
+	_, err := os.Stat("main.go")
+	return err == nil
+}
+
+// runInProject runs the project's main package with args, so that commands
+// needing the project's models and routes execute with them linked in.
+func runInProject(args ...string) error {
+	slog.Debug("running project command", "args", args)
+
+	cmd := exec.Command("go", append([]string{"run", "."}, args...)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// outputInProject is like runInProject but returns the command's standard
+// output instead of printing it.
+func outputInProject(args ...string) ([]byte, error) {
+	slog.Debug("running project command", "args", args)
+
+	cmd := exec.Command("go", append([]string{"run", "."}, args...)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	return cmd.Output()
+}
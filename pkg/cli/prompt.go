@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ThreadBolt/threadbolt/pkg/generator"
+	"golang.org/x/term"
+)
+
+// stdin is shared by the prompts so that input read ahead by one is not lost
+// to the next.
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm asks a yes/no question on the terminal, defaulting to no. It
+// returns false without asking when stdin is not a terminal.
+func confirm(question string) bool {
+	if !isTerminal() {
+		return false
+	}
+
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := stdin.ReadString('\n')
+	if err != nil {
+		fmt.Println()
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// confirmOverwrite asks whether to overwrite path, printing diff if asked to.
+func confirmOverwrite(path, diff string) bool {
+	for {
+		fmt.Printf("%s already exists. Overwrite? [y/N/d(iff)] ", path)
+		answer, err := stdin.ReadString('\n')
+		if err != nil {
+			fmt.Println()
+			return false
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return true
+		case "d", "diff":
+			fmt.Print(diff)
+		default:
+			return false
+		}
+	}
+}
+
+// isTerminal reports whether stdin is a terminal that can be prompted.
+// Character devices such as /dev/null are not.
+func isTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// promptVariable asks for the value of a project template variable; an empty
+// or unreadable answer keeps its default.
+func promptVariable(variable generator.TemplateVariable) (string, error) {
+	question := variable.Prompt
+	if question == "" {
+		question = variable.Name
+	}
+	if len(variable.Choices) > 0 {
+		question += " (" + strings.Join(variable.Choices, ", ") + ")"
+	}
+	if variable.Default != "" {
+		question += " [" + variable.Default + "]"
+	}
+
+	fmt.Printf("%s: ", question)
+	answer, err := stdin.ReadString('\n')
+	if err != nil {
+		fmt.Println()
+		return "", nil
+	}
+	return strings.TrimSpace(answer), nil
+}
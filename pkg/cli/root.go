@@ -1,6 +1,10 @@
 package cli
 
 import (
+	"log/slog"
+	"os"
+
+	"github.com/ThreadBolt/threadbolt/pkg/log"
 	"github.com/spf13/cobra"
 )
 
@@ -9,6 +13,9 @@ var rootCmd = &cobra.Command{
 	Short: "ThreadBolt is a Go web framework inspired by Spring Boot",
 	Long: `ThreadBolt is a convention-over-configuration web framework for Go that provides
 MVC architecture, built-in ORM, dependency injection, and CLI tools for rapid development.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return setupLogging(cmd)
+	},
 }
 
 func Execute() error {
@@ -16,9 +23,40 @@ func Execute() error {
 }
 
 func init() {
+	rootCmd.PersistentFlags().String("log-level", "", "Log level (debug, info, warn, error); overrides logging.level")
+	rootCmd.PersistentFlags().String("log-format", "", "Log format (text, json); overrides logging.format")
+
 	rootCmd.AddCommand(newCmd)
 	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(destroyCmd)
+	rootCmd.AddCommand(templatesCmd)
 	rootCmd.AddCommand(migrateCmd)
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(testCmd)
-}
\ No newline at end of file
+}
+
+// setupLogging installs the CLI's default logger. Flag values are exported as
+// THREADBOLT_LOGGING_* variables so that the application's configuration and
+// any project process started by the CLI use the same settings.
+func setupLogging(cmd *cobra.Command) error {
+	level, _ := cmd.Flags().GetString("log-level")
+	format, _ := cmd.Flags().GetString("log-format")
+
+	if level != "" {
+		os.Setenv("THREADBOLT_LOGGING_LEVEL", level)
+	}
+	if format != "" {
+		os.Setenv("THREADBOLT_LOGGING_FORMAT", format)
+	}
+
+	logger, err := log.New(log.Config{
+		Level:  os.Getenv("THREADBOLT_LOGGING_LEVEL"),
+		Format: os.Getenv("THREADBOLT_LOGGING_FORMAT"),
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.SetDefault(logger)
+	return nil
+}
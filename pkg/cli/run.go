@@ -4,30 +4,44 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/spf13/cobra"
 	"github.com/ThreadBolt/threadbolt/pkg/framework"
+	"github.com/spf13/cobra"
 )
 
 var runCmd = &cobra.Command{
 	Use:   "run",
 	Short: "Start the ThreadBolt application server",
 	Run: func(cmd *cobra.Command, args []string) {
+		port, _ := cmd.Flags().GetString("port")
+
+		// Run the project's own main package so that the routes it passes
+		// to framework.LoadApp are installed.
+		if inProject() {
+			if port != "" {
+				os.Setenv("THREADBOLT_SERVER_PORT", port)
+			}
+			if err := runInProject(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error running application: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		app, err := framework.LoadApp()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error loading app: %v\n", err)
 			os.Exit(1)
 		}
-		
-		port, _ := cmd.Flags().GetString("port")
+
 		if port == "" {
 			port = app.Config.GetString("server.port")
 		}
 		if port == "" {
 			port = "8080"
 		}
-		
+
 		fmt.Printf("🚀 Starting ThreadBolt application on port %s\n", port)
-		
+
 		if err := app.Start(port); err != nil {
 			fmt.Fprintf(os.Stderr, "Error starting server: %v\n", err)
 			os.Exit(1)
@@ -37,4 +51,4 @@ var runCmd = &cobra.Command{
 
 func init() {
 	runCmd.Flags().StringP("port", "p", "", "Port to run the server on")
-}
\ No newline at end of file
+}
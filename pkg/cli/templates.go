@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ThreadBolt/threadbolt/pkg/generator"
+	"github.com/spf13/cobra"
+)
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Manage the templates generators render",
+	Long: `Generators render the project's own templates from .threadbolt/templates,
+such as .threadbolt/templates/model.tmpl, in place of the built-in ones. Eject
+the built-in templates there to adapt them to the project's style.
+
+Besides the data each generator passes, templates can use the functions
+pluralize, snake_case and camelCase:
+
+  {{.ModelName | snake_case | pluralize}}   BlogPost -> blog_posts`,
+}
+
+var templatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the templates and whether the project overrides them",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, name := range generator.TemplateNames() {
+			file := filepath.Join(generator.TemplateDir, filepath.FromSlash(name)+".tmpl")
+			if _, err := os.Stat(file); err == nil {
+				fmt.Printf("%-20s %s\n", name, file)
+			} else {
+				fmt.Printf("%-20s built-in\n", name)
+			}
+		}
+	},
+}
+
+var templatesEjectCmd = &cobra.Command{
+	Use:   "eject [name...]",
+	Short: "Copy built-in templates into the project for editing",
+	Long: `Copies the named built-in templates, or all of them, to .threadbolt/templates,
+where generators pick them up instead of the built-in ones. Run
+'threadbolt templates list' for their names.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupLogging(cmd); err != nil {
+			return err
+		}
+		return applyWriteFlags(cmd)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if !inProject() {
+			fmt.Fprintln(os.Stderr, "Error ejecting templates: must be run in the root of a ThreadBolt project")
+			os.Exit(1)
+		}
+
+		files, err := generator.EjectTemplates(args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error ejecting templates: %v\n", err)
+			os.Exit(1)
+		}
+
+		if generated("Ejected templates:") {
+			for _, file := range files {
+				fmt.Printf("   %s\n", file)
+			}
+		}
+	},
+}
+
+func init() {
+	addWriteFlags(templatesEjectCmd.Flags())
+	templatesCmd.AddCommand(templatesListCmd)
+	templatesCmd.AddCommand(templatesEjectCmd)
+}
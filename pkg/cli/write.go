@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ThreadBolt/threadbolt/pkg/generator"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// dryRun is set when a command runs its generators with --dry-run.
+var dryRun bool
+
+// addWriteFlags adds the flags deciding what generators do about existing
+// files.
+func addWriteFlags(flags *pflag.FlagSet) {
+	flags.Bool("force", false, "Overwrite existing files that differ from the generated ones")
+	flags.Bool("skip", false, "Keep existing files that differ from the generated ones")
+	flags.Bool("dry-run", false, "List the files that would change without writing them")
+	flags.Bool("diff", false, "Show a unified diff of the changes to existing files")
+}
+
+// applyWriteFlags passes the write flags cmd has to the generators. Without
+// --force or --skip, conflicting files are only overwritten after asking on a
+// terminal.
+func applyWriteFlags(cmd *cobra.Command) error {
+	var opts generator.WriteOptions
+	opts.Force, _ = cmd.Flags().GetBool("force")
+	opts.Skip, _ = cmd.Flags().GetBool("skip")
+	opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	opts.Diff, _ = cmd.Flags().GetBool("diff")
+
+	if opts.Force && opts.Skip {
+		return fmt.Errorf("--force and --skip cannot be used together")
+	}
+	if isTerminal() {
+		opts.Confirm = confirmOverwrite
+	}
+
+	generator.SetWriteOptions(opts)
+	dryRun = opts.DryRun
+	return nil
+}
+
+// generated prints a generator's success message, or that nothing was
+// written on a dry run or because every file was kept. It reports whether
+// files were written, so that steps depending on them, such as migrations,
+// are only run then.
+func generated(format string, args ...interface{}) bool {
+	if dryRun {
+		fmt.Println("Dry run: no files were changed.")
+		return false
+	}
+	if generator.SkippedAll() {
+		fmt.Println("Every file was kept; nothing was generated.")
+		return false
+	}
+	fmt.Printf("✅ "+format+"\n", args...)
+	return true
+}
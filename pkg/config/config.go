@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
@@ -22,6 +23,7 @@ func Load() (*viper.Viper, error) {
 	// Environment variable settings
 	v.AutomaticEnv()
 	v.SetEnvPrefix("THREADBOLT")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
 	// Default values
 	setDefaults(v)
@@ -43,6 +45,7 @@ func setDefaults(v *viper.Viper) {
 	// Server defaults
 	v.SetDefault("server.port", "8080")
 	v.SetDefault("server.host", "localhost")
+	v.SetDefault("server.shutdown_timeout", "30s")
 
 	// Database defaults
 	v.SetDefault("database.driver", "sqlite")
@@ -56,4 +59,46 @@ func setDefaults(v *viper.Viper) {
 	// Logging defaults
 	v.SetDefault("logging.level", "info")
 	v.SetDefault("logging.format", "text")
+
+	// Middleware defaults
+	v.SetDefault("middleware.request_id.enabled", true)
+	v.SetDefault("middleware.request_id.header", "X-Request-ID")
+	v.SetDefault("middleware.real_ip.enabled", true)
+	v.SetDefault("middleware.real_ip.trusted_proxies", []string{})
+	v.SetDefault("middleware.access_log.enabled", true)
+	v.SetDefault("middleware.recovery.enabled", true)
+	v.SetDefault("middleware.security_headers.enabled", true)
+	v.SetDefault("middleware.security_headers.content_type_options", "nosniff")
+	v.SetDefault("middleware.security_headers.frame_options", "DENY")
+	v.SetDefault("middleware.security_headers.referrer_policy", "strict-origin-when-cross-origin")
+	v.SetDefault("middleware.security_headers.content_security_policy", "")
+	v.SetDefault("middleware.security_headers.hsts_max_age", "0s")
+	v.SetDefault("middleware.body_limit.enabled", true)
+	v.SetDefault("middleware.body_limit.max_bytes", 10<<20)
+	v.SetDefault("middleware.compression.enabled", true)
+	v.SetDefault("middleware.compression.level", 0)
+	v.SetDefault("middleware.compression.min_size", 1024)
+	v.SetDefault("middleware.timeout.enabled", false)
+	v.SetDefault("middleware.timeout.duration", "30s")
+
+	// CORS defaults
+	v.SetDefault("cors.enabled", false)
+	v.SetDefault("cors.allowed_origins", []string{})
+	v.SetDefault("cors.allowed_methods", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"})
+	v.SetDefault("cors.allowed_headers", []string{"Content-Type", "Authorization"})
+	v.SetDefault("cors.exposed_headers", []string{})
+	v.SetDefault("cors.allow_credentials", false)
+	v.SetDefault("cors.max_age", "0s")
+
+	// Auth defaults
+	v.SetDefault("auth.jwt.enabled", false)
+	v.SetDefault("auth.jwt.issuer", "")
+	v.SetDefault("auth.jwt.access_ttl", "15m")
+	v.SetDefault("auth.jwt.refresh_ttl", "720h")
+	v.SetDefault("auth.jwt.signing_key", "")
+	v.SetDefault("auth.session.enabled", false)
+	v.SetDefault("auth.session.cookie_name", "threadbolt_session")
+	v.SetDefault("auth.session.max_age", "24h")
+	v.SetDefault("auth.session.secure", false)
+	v.SetDefault("auth.session.same_site", "lax")
 }
@@ -1,19 +1,31 @@
 package di
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"sync"
 )
 
 type Container struct {
-	services map[string]interface{}
-	mutex    sync.RWMutex
+	services  map[string]interface{}
+	providers map[reflect.Type]*provider
+	named     map[string]*provider
+	mutex     sync.RWMutex
+
+	parent      *Container
+	scoped      map[*provider]*instanceCell
+	disposables []io.Closer
+	scopeMutex  sync.Mutex
 }
 
 func NewContainer() *Container {
 	return &Container{
-		services: make(map[string]interface{}),
+		services:  make(map[string]interface{}),
+		providers: make(map[reflect.Type]*provider),
+		named:     make(map[string]*provider),
+		scoped:    make(map[*provider]*instanceCell),
 	}
 }
 
@@ -24,15 +36,25 @@ func (c *Container) Register(name string, service interface{}) {
 }
 
 func (c *Container) Get(name string) (interface{}, error) {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
+	for scope := c; scope != nil; scope = scope.parent {
+		scope.mutex.RLock()
+		service, exists := scope.services[name]
+		p, named := scope.named[name]
+		scope.mutex.RUnlock()
 
-	service, exists := c.services[name]
-	if !exists {
-		return nil, fmt.Errorf("service '%s' not found", name)
+		if exists {
+			return service, nil
+		}
+		if named {
+			value, err := c.instantiate(p, []reflect.Type{p.outType})
+			if err != nil {
+				return nil, err
+			}
+			return value.Interface(), nil
+		}
 	}
 
-	return service, nil
+	return nil, fmt.Errorf("service '%s' not found", name)
 }
 
 func (c *Container) GetTyped(name string, target interface{}) error {
@@ -42,37 +64,44 @@ func (c *Container) GetTyped(name string, target interface{}) error {
 	}
 
 	targetValue := reflect.ValueOf(target)
-	if targetValue.Kind() != reflect.Ptr || targetValue.Elem().Kind() != reflect.Interface {
-		return fmt.Errorf("target must be a pointer to an interface")
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() {
+		return fmt.Errorf("target must be a non-nil pointer")
+	}
+
+	serviceValue := reflect.ValueOf(service)
+	if !serviceValue.IsValid() || !serviceValue.Type().AssignableTo(targetValue.Elem().Type()) {
+		return fmt.Errorf("service '%s' of type %T is not assignable to %s", name, service, targetValue.Elem().Type())
 	}
 
-	targetValue.Elem().Set(reflect.ValueOf(service))
+	targetValue.Elem().Set(serviceValue)
 	return nil
 }
 
-func (c *Container) Inject(target interface{}) error {
-	targetValue := reflect.ValueOf(target)
-	if targetValue.Kind() != reflect.Ptr {
-		return fmt.Errorf("target must be a pointer")
-	}
-
-	targetType := targetValue.Elem().Type()
-	for i := 0; i < targetType.NumField(); i++ {
-		field := targetType.Field(i)
-		injectTag := field.Tag.Get("inject")
+// Scope returns a child container for a unit of work such as an HTTP request.
+// The scope sees every registration of its parent, keeps its own instances of
+// Scoped providers, and may register services of its own, such as the current
+// user, without affecting the parent.
+func (c *Container) Scope() *Container {
+	scope := NewContainer()
+	scope.parent = c
+	return scope
+}
 
-		if injectTag != "" {
-			service, err := c.Get(injectTag)
-			if err != nil {
-				return fmt.Errorf("failed to inject %s: %w", injectTag, err)
-			}
+// Close closes, in reverse creation order, every instance this container
+// constructed that implements io.Closer: scoped instances for a scope, and
+// singletons for the container they were provided to.
+func (c *Container) Close() error {
+	c.scopeMutex.Lock()
+	disposables := c.disposables
+	c.disposables = nil
+	c.scopeMutex.Unlock()
 
-			fieldValue := targetValue.Elem().Field(i)
-			if fieldValue.CanSet() {
-				fieldValue.Set(reflect.ValueOf(service))
-			}
+	var errs []error
+	for i := len(disposables) - 1; i >= 0; i-- {
+		if err := disposables[i].Close(); err != nil {
+			errs = append(errs, err)
 		}
 	}
 
-	return nil
-}
\ No newline at end of file
+	return errors.Join(errs...)
+}
@@ -0,0 +1,43 @@
+package di
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx that carries c.
+func NewContext(ctx context.Context, c *Container) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the container stored in ctx by NewContext or
+// ScopeMiddleware, or nil if there is none.
+func FromContext(ctx context.Context) *Container {
+	c, _ := ctx.Value(contextKey{}).(*Container)
+	return c
+}
+
+// ScopeMiddleware creates a scope of c for every request, registers the
+// request in it as "request", and stores the scope in the request context
+// where handlers can reach it with FromContext. The scope is closed once the
+// handler returns.
+func ScopeMiddleware(c *Container) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope := c.Scope()
+			defer func() {
+				if err := scope.Close(); err != nil {
+					slog.ErrorContext(r.Context(), "failed to close request scope", "error", err)
+				}
+			}()
+
+			r = r.WithContext(NewContext(r.Context(), scope))
+			scope.Register("request", r)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
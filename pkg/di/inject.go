@@ -0,0 +1,140 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// InjectError reports every field that Inject could not populate.
+type InjectError struct {
+	Target reflect.Type
+	Errors []error
+}
+
+func (e *InjectError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "failed to inject %d field(s) into %s:", len(e.Errors), e.Target)
+	for _, err := range e.Errors {
+		b.WriteString("\n  ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+func (e *InjectError) Unwrap() []error {
+	return e.Errors
+}
+
+// Inject populates the fields of the struct pointed to by target that carry
+// an inject tag:
+//
+//	Users  *services.UserService `inject:"userService"` // by name
+//	Mailer services.Mailer       `inject:""`            // by type
+//	Cache  *cache.Client         `inject:",optional"`   // left nil if unavailable
+//
+// Embedded structs are injected recursively. Every field that cannot be
+// injected is reported in a single *InjectError.
+func (c *Container) Inject(target interface{}) error {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() {
+		return fmt.Errorf("target must be a non-nil pointer to a struct, got %T", target)
+	}
+	if targetValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("target must be a pointer to a struct, got %T", target)
+	}
+
+	var errs []error
+	c.injectStruct(targetValue.Elem(), "", &errs)
+	if len(errs) > 0 {
+		return &InjectError{Target: targetValue.Type(), Errors: errs}
+	}
+
+	return nil
+}
+
+func (c *Container) injectStruct(structValue reflect.Value, prefix string, errs *[]error) {
+	structType := structValue.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldValue := structValue.Field(i)
+		fieldPath := prefix + field.Name
+
+		tag, tagged := field.Tag.Lookup("inject")
+		if !tagged {
+			if field.Anonymous && field.IsExported() {
+				if embedded, ok := embeddedStruct(fieldValue); ok {
+					c.injectStruct(embedded, fieldPath+".", errs)
+				}
+			}
+			continue
+		}
+
+		name, optional := parseInjectTag(tag)
+
+		if !fieldValue.CanSet() {
+			*errs = append(*errs, fmt.Errorf("%s: cannot inject into unexported field", fieldPath))
+			continue
+		}
+
+		service, err := c.lookup(name, field.Type)
+		if err != nil {
+			if !optional {
+				*errs = append(*errs, fmt.Errorf("%s: %w", fieldPath, err))
+			}
+			continue
+		}
+
+		if !service.IsValid() || !service.Type().AssignableTo(field.Type) {
+			*errs = append(*errs, fmt.Errorf("%s: service '%s' of type %s is not assignable to %s",
+				fieldPath, name, typeName(service), field.Type))
+			continue
+		}
+
+		fieldValue.Set(service)
+	}
+}
+
+// lookup returns the service called name, or the service of type t when name
+// is empty.
+func (c *Container) lookup(name string, t reflect.Type) (reflect.Value, error) {
+	if name == "" {
+		return c.resolve(t, nil)
+	}
+
+	service, err := c.Get(name)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(service), nil
+}
+
+// embeddedStruct returns the struct value of an embedded field, following a
+// non-nil pointer.
+func embeddedStruct(fieldValue reflect.Value) (reflect.Value, bool) {
+	if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.IsNil() {
+			return reflect.Value{}, false
+		}
+		fieldValue = fieldValue.Elem()
+	}
+	return fieldValue, fieldValue.Kind() == reflect.Struct
+}
+
+func parseInjectTag(tag string) (name string, optional bool) {
+	parts := strings.Split(tag, ",")
+	for _, option := range parts[1:] {
+		if strings.TrimSpace(option) == "optional" {
+			optional = true
+		}
+	}
+	return strings.TrimSpace(parts[0]), optional
+}
+
+func typeName(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	return v.Type().String()
+}
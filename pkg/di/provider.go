@@ -0,0 +1,423 @@
+package di
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Lifetime controls how often a provider's constructor is called.
+type Lifetime int
+
+const (
+	// Singleton providers are constructed once, on first use, and shared.
+	Singleton Lifetime = iota
+	// Transient providers are constructed every time they are resolved.
+	Transient
+	// Scoped providers are constructed once per scope, such as an HTTP
+	// request.
+	Scoped
+)
+
+func (l Lifetime) String() string {
+	switch l {
+	case Singleton:
+		return "singleton"
+	case Transient:
+		return "transient"
+	case Scoped:
+		return "scoped"
+	default:
+		return fmt.Sprintf("Lifetime(%d)", int(l))
+	}
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// ProvideOption configures a provider registered with Provide.
+type ProvideOption func(*provider)
+
+// AsTransient constructs a new instance every time the type is resolved.
+func AsTransient() ProvideOption {
+	return func(p *provider) {
+		p.lifetime = Transient
+	}
+}
+
+// AsScoped constructs one instance per scope. Resolving it from a container
+// that is not a scope fails.
+func AsScoped() ProvideOption {
+	return func(p *provider) {
+		p.lifetime = Scoped
+	}
+}
+
+// Named also makes the provider available by name through Get and
+// `inject:"name"` struct tags.
+func Named(name string) ProvideOption {
+	return func(p *provider) {
+		p.name = name
+	}
+}
+
+type provider struct {
+	constructor reflect.Value
+	outType     reflect.Type
+	params      []reflect.Type
+	returnsErr  bool
+	lifetime    Lifetime
+	name        string
+	owner       *Container
+
+	// singleton holds the shared instance of a Singleton provider.
+	singleton instanceCell
+}
+
+// instanceCell lazily holds a single constructed instance.
+type instanceCell struct {
+	mutex    sync.Mutex
+	built    bool
+	instance reflect.Value
+}
+
+func (cell *instanceCell) get(build func() (reflect.Value, error)) (reflect.Value, error) {
+	cell.mutex.Lock()
+	defer cell.mutex.Unlock()
+
+	if cell.built {
+		return cell.instance, nil
+	}
+
+	instance, err := build()
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	cell.instance, cell.built = instance, true
+
+	return instance, nil
+}
+
+// Provide registers a constructor such as NewUserService. The constructor
+// must return the service, optionally followed by an error; its parameters
+// are resolved by type when the service is first needed. Providers are
+// singletons unless AsTransient or AsScoped is given.
+func (c *Container) Provide(constructor interface{}, opts ...ProvideOption) error {
+	p, err := newProvider(constructor)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.owner = c
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, exists := c.providers[p.outType]; exists {
+		return fmt.Errorf("a provider for %s is already registered", p.outType)
+	}
+	c.providers[p.outType] = p
+	if p.name != "" {
+		c.named[p.name] = p
+	}
+
+	return nil
+}
+
+func newProvider(constructor interface{}) (*provider, error) {
+	fn := reflect.ValueOf(constructor)
+	if fn.Kind() != reflect.Func {
+		return nil, fmt.Errorf("constructor must be a function, got %T", constructor)
+	}
+
+	fnType := fn.Type()
+	if fnType.IsVariadic() {
+		return nil, fmt.Errorf("constructor %s must not be variadic", fnType)
+	}
+
+	switch {
+	case fnType.NumOut() == 1 && fnType.Out(0) != errorType:
+	case fnType.NumOut() == 2 && fnType.Out(1) == errorType:
+	default:
+		return nil, fmt.Errorf("constructor %s must return a service and optionally an error", fnType)
+	}
+
+	p := &provider{
+		constructor: fn,
+		outType:     fnType.Out(0),
+		returnsErr:  fnType.NumOut() == 2,
+	}
+	for i := 0; i < fnType.NumIn(); i++ {
+		p.params = append(p.params, fnType.In(i))
+	}
+
+	return p, nil
+}
+
+// Invoke calls fn with its parameters resolved from the container. If fn
+// returns an error as its last result, that error is returned.
+func (c *Container) Invoke(fn interface{}) error {
+	fnValue := reflect.ValueOf(fn)
+	if fnValue.Kind() != reflect.Func {
+		return fmt.Errorf("invoke target must be a function, got %T", fn)
+	}
+
+	fnType := fnValue.Type()
+	args := make([]reflect.Value, fnType.NumIn())
+	for i := range args {
+		arg, err := c.resolve(fnType.In(i), nil)
+		if err != nil {
+			return err
+		}
+		args[i] = arg
+	}
+
+	results := fnValue.Call(args)
+	if n := len(results); n > 0 && fnType.Out(n-1) == errorType && !results[n-1].IsNil() {
+		return results[n-1].Interface().(error)
+	}
+
+	return nil
+}
+
+// Resolve returns the service of type T, constructing it and its
+// dependencies as needed.
+func Resolve[T any](c *Container) (T, error) {
+	var zero T
+	value, err := c.resolve(reflect.TypeOf((*T)(nil)).Elem(), nil)
+	if err != nil {
+		return zero, err
+	}
+	return value.Interface().(T), nil
+}
+
+// MustResolve is like Resolve but panics if the service cannot be resolved.
+// It is intended for application wiring code.
+func MustResolve[T any](c *Container) T {
+	service, err := Resolve[T](c)
+	if err != nil {
+		panic(err)
+	}
+	return service
+}
+
+// resolve returns a value of type t. path holds the types currently being
+// constructed and is used to report cycles and missing dependencies.
+func (c *Container) resolve(t reflect.Type, path []reflect.Type) (reflect.Value, error) {
+	for _, seen := range path {
+		if seen == t {
+			return reflect.Value{}, fmt.Errorf("dependency cycle detected: %s", formatPath(append(path, t)))
+		}
+	}
+
+	p, err := c.findProvider(t)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%w (path: %s)", err, formatPath(append(path, t)))
+	}
+	if p != nil {
+		if p.outType != t {
+			// An interface resolved through the provider of an implementing
+			// type, which may already be under construction.
+			path = append(path, t)
+			t = p.outType
+			for _, seen := range path {
+				if seen == t {
+					return reflect.Value{}, fmt.Errorf("dependency cycle detected: %s", formatPath(append(path, t)))
+				}
+			}
+		}
+		return c.instantiate(p, append(path, t))
+	}
+
+	service, err := c.findService(t)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%w (path: %s)", err, formatPath(append(path, t)))
+	}
+
+	return service, nil
+}
+
+// findProvider returns the provider for t from this container or the nearest
+// ancestor that has one. Interfaces are satisfied by a provider of an
+// implementing type when exactly one exists at that level.
+func (c *Container) findProvider(t reflect.Type) (*provider, error) {
+	for scope := c; scope != nil; scope = scope.parent {
+		p, err := scope.ownProvider(t)
+		if err != nil || p != nil {
+			return p, err
+		}
+	}
+	return nil, nil
+}
+
+func (c *Container) ownProvider(t reflect.Type) (*provider, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if p, ok := c.providers[t]; ok {
+		return p, nil
+	}
+	if t.Kind() != reflect.Interface {
+		return nil, nil
+	}
+
+	var matches []*provider
+	for outType, p := range c.providers {
+		if outType.Implements(t) {
+			matches = append(matches, p)
+		}
+	}
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("multiple providers implement %s", t)
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+
+	return nil, nil
+}
+
+// findService returns the single registered service assignable to t from
+// this container or the nearest ancestor that has one.
+func (c *Container) findService(t reflect.Type) (reflect.Value, error) {
+	for scope := c; scope != nil; scope = scope.parent {
+		service, err := scope.ownService(t)
+		if err != nil || service.IsValid() {
+			return service, err
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("no provider for %s", t)
+}
+
+func (c *Container) ownService(t reflect.Type) (reflect.Value, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	var match reflect.Value
+	var matchName string
+	for name, service := range c.services {
+		value := reflect.ValueOf(service)
+		if !value.IsValid() || !value.Type().AssignableTo(t) {
+			continue
+		}
+		if match.IsValid() {
+			return reflect.Value{}, fmt.Errorf("services '%s' and '%s' both provide %s", matchName, name, t)
+		}
+		match, matchName = value, name
+	}
+
+	return match, nil
+}
+
+// instantiate returns an instance from p according to its lifetime.
+// Singletons are built by the container that registered them, so their
+// dependencies never come from a shorter-lived scope; scoped instances are
+// built and cached by the resolving scope.
+func (c *Container) instantiate(p *provider, path []reflect.Type) (reflect.Value, error) {
+	switch p.lifetime {
+	case Transient:
+		return c.construct(p, path)
+	case Scoped:
+		if c.parent == nil {
+			// The root container lives as long as the application, so
+			// caching the instance there would make it a singleton.
+			return reflect.Value{}, fmt.Errorf("scoped %s must be resolved from a scope, such as di.FromContext(r.Context()) (path: %s)",
+				p.outType, formatPath(path))
+		}
+		return c.scopedCell(p).get(func() (reflect.Value, error) {
+			return c.constructTracked(p, path)
+		})
+	default:
+		return p.singleton.get(func() (reflect.Value, error) {
+			return p.owner.constructTracked(p, path)
+		})
+	}
+}
+
+func (c *Container) scopedCell(p *provider) *instanceCell {
+	c.scopeMutex.Lock()
+	defer c.scopeMutex.Unlock()
+
+	cell, ok := c.scoped[p]
+	if !ok {
+		cell = &instanceCell{}
+		c.scoped[p] = cell
+	}
+	return cell
+}
+
+// constructTracked constructs an instance owned by c, to be closed by
+// c.Close if it implements io.Closer.
+func (c *Container) constructTracked(p *provider, path []reflect.Type) (reflect.Value, error) {
+	instance, err := c.construct(p, path)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	if closer, ok := instance.Interface().(io.Closer); ok {
+		c.scopeMutex.Lock()
+		c.disposables = append(c.disposables, closer)
+		c.scopeMutex.Unlock()
+	}
+
+	return instance, nil
+}
+
+func (c *Container) construct(p *provider, path []reflect.Type) (reflect.Value, error) {
+	if p.lifetime == Singleton {
+		// A singleton would otherwise keep the first scope's instance alive
+		// for the lifetime of the container.
+		if chain := c.scopedDependency(p, make(map[*provider]bool)); chain != nil {
+			return reflect.Value{}, fmt.Errorf("singleton %s cannot depend on scoped %s (path: %s)",
+				p.outType, chain[len(chain)-1], formatPath(append(path, chain...)))
+		}
+	}
+
+	args := make([]reflect.Value, len(p.params))
+	for i, param := range p.params {
+		arg, err := c.resolve(param, path)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		args[i] = arg
+	}
+
+	results := p.constructor.Call(args)
+	if p.returnsErr && !results[1].IsNil() {
+		return reflect.Value{}, fmt.Errorf("failed to construct %s: %w", p.outType, results[1].Interface().(error))
+	}
+
+	return results[0], nil
+}
+
+// scopedDependency returns the types through which p depends on a scoped
+// provider, directly or through transient ones, or nil if it does not.
+func (c *Container) scopedDependency(p *provider, visited map[*provider]bool) []reflect.Type {
+	visited[p] = true
+	for _, param := range p.params {
+		dep, _ := c.findProvider(param)
+		if dep == nil || visited[dep] {
+			continue
+		}
+		switch dep.lifetime {
+		case Scoped:
+			return []reflect.Type{param}
+		case Transient:
+			if chain := c.scopedDependency(dep, visited); chain != nil {
+				return append([]reflect.Type{param}, chain...)
+			}
+		}
+	}
+	return nil
+}
+
+func formatPath(path []reflect.Type) string {
+	names := make([]string, len(path))
+	for i, t := range path {
+		names[i] = t.String()
+	}
+	return strings.Join(names, " -> ")
+}
@@ -0,0 +1,96 @@
+package di
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type greeter interface{ Greet() string }
+
+type cycleX struct{ b *cycleB }
+
+func (x *cycleX) Greet() string { return "x" }
+
+type cycleB struct{ g greeter }
+
+func TestResolveReportsCycleThroughInterface(t *testing.T) {
+	c := NewContainer()
+	if err := c.Provide(func(b *cycleB) *cycleX { return &cycleX{b: b} }); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Provide(func(g greeter) *cycleB { return &cycleB{g: g} }); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := Resolve[*cycleX](c)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "dependency cycle detected") {
+			t.Fatalf("got error %v, want a dependency cycle", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Resolve deadlocked on a cycle through an interface")
+	}
+}
+
+type (
+	scopedDep    struct{}
+	transientDep struct{ s *scopedDep }
+	singletonDep struct{ t *transientDep }
+)
+
+func TestSingletonCannotDependOnScopedThroughTransient(t *testing.T) {
+	c := NewContainer()
+	if err := c.Provide(func() *scopedDep { return &scopedDep{} }, AsScoped()); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Provide(func(s *scopedDep) *transientDep { return &transientDep{s: s} }, AsTransient()); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Provide(func(t *transientDep) *singletonDep { return &singletonDep{t: t} }); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Resolve[*singletonDep](c.Scope())
+	if err == nil || !strings.Contains(err.Error(), "cannot depend on scoped") {
+		t.Fatalf("got error %v, want a lifetime error", err)
+	}
+
+	// Transients may still depend on scoped providers.
+	if _, err := Resolve[*transientDep](c.Scope()); err != nil {
+		t.Fatalf("failed to resolve transient: %v", err)
+	}
+}
+
+// requestState is not zero-sized, so distinct instances have distinct
+// addresses.
+type requestState struct{ id int }
+
+func TestResolveScopedRequiresScope(t *testing.T) {
+	c := NewContainer()
+	if err := c.Provide(func() *requestState { return &requestState{} }, AsScoped()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Resolve[*requestState](c); err == nil || !strings.Contains(err.Error(), "must be resolved from a scope") {
+		t.Fatalf("got error %v, want a scope error", err)
+	}
+
+	scope := c.Scope()
+	first, err := Resolve[*requestState](scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second := MustResolve[*requestState](scope); second != first {
+		t.Error("scope built a second instance")
+	}
+	if other := MustResolve[*requestState](c.Scope()); other == first {
+		t.Error("scopes share an instance")
+	}
+}
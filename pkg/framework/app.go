@@ -1,18 +1,25 @@
 package framework
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"sync"
 
 	"github.com/gorilla/mux"
 	"github.com/spf13/viper"
 	"gorm.io/gorm"
 
+	"github.com/ThreadBolt/threadbolt/pkg/auth"
 	"github.com/ThreadBolt/threadbolt/pkg/config"
 	"github.com/ThreadBolt/threadbolt/pkg/di"
+	"github.com/ThreadBolt/threadbolt/pkg/log"
+	"github.com/ThreadBolt/threadbolt/pkg/middleware"
 	"github.com/ThreadBolt/threadbolt/pkg/orm"
+	"github.com/ThreadBolt/threadbolt/pkg/validation"
 )
 
 type App struct {
@@ -20,54 +27,223 @@ type App struct {
 	DB        *gorm.DB
 	Config    *viper.Viper
 	Container *di.Container
+	Logger    *slog.Logger
+	Server    *http.Server
+	// Permissions holds the permissions granted to roles under auth.roles
+	// and those annotated on routes.
+	Permissions *auth.Permissions
+
+	handler    http.Handler
+	onStart    []Hook
+	onStop     []Hook
+	hooksMutex sync.Mutex
+}
+
+// LoadApp builds an App from the project in the working directory following
+// ThreadBolt's conventions: the project layout is validated, configuration is
+// read from config/config.yaml and the environment, and the configured
+// database is opened unless database.driver is "none". Options override any
+// of these steps. The application's logger also becomes the slog default, so
+// packages logging through log/slog or the standard log package share its
+// level and format.
+func LoadApp(opts ...Option) (*App, error) {
+	app, err := New(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.SetDefault(app.Logger)
+	return app, nil
 }
 
-func LoadApp() (*App, error) {
+// New builds an App from the given options. Components that are not injected
+// are created from configuration; an injected database is left open on
+// shutdown, as its lifetime belongs to the caller.
+func New(opts ...Option) (*App, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	// Validate project structure
-	if err := validateProjectStructure(); err != nil {
-		return nil, fmt.Errorf("invalid project structure: %w", err)
+	if !o.skipValidation {
+		if err := validateProjectStructure(); err != nil {
+			return nil, fmt.Errorf("invalid project structure: %w", err)
+		}
 	}
 
 	app := &App{
-		Router:    mux.NewRouter(),
-		Container: di.NewContainer(),
+		Router:      o.router,
+		Container:   o.container,
+		Config:      o.config,
+		DB:          o.db,
+		Logger:      o.logger,
+		Permissions: auth.NewPermissions(),
+	}
+	if app.Router == nil {
+		app.Router = mux.NewRouter()
+	}
+	if app.Container == nil {
+		app.Container = di.NewContainer()
 	}
 
 	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load config: %w", err)
+	if app.Config == nil {
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		app.Config = cfg
+	}
+
+	// Initialize logging
+	if app.Logger == nil {
+		logger, err := log.FromConfig(app.Config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize logger: %w", err)
+		}
+		app.Logger = logger
 	}
-	app.Config = cfg
 
 	// Initialize database
-	db, err := orm.Initialize(cfg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	if app.DB == nil && app.Config.GetString("database.driver") != "none" {
+		db, err := orm.InitializeWithLogger(app.Config, app.Logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize database: %w", err)
+		}
+		app.DB = db
+		app.OnStop(func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.Close()
+		})
+	}
+
+	// Close services built by our own container before the database they
+	// may depend on; stop hooks run in reverse registration order.
+	if o.container == nil {
+		app.OnStop(func(ctx context.Context) error {
+			return app.Container.Close()
+		})
 	}
-	app.DB = db
 
-	// Register database in DI container
-	app.Container.Register("db", db)
+	// Register database, configuration, logger and validator in DI container
+	if app.DB != nil {
+		app.Container.Register("db", app.DB)
+	}
+	app.Container.Register("config", app.Config)
+	app.Container.Register("logger", app.Logger)
+	app.Container.Register("validator", validation.New())
+	app.Container.Register("auth.permissions", app.Permissions)
+
+	// Install the built-in middleware and authenticators enabled in
+	// configuration, then give every request its own DI scope holding the
+	// principal the authenticators found
+	app.handler = app.Router
+	if !o.skipMiddleware {
+		mwConfig := middleware.LoadConfig(app.Config)
+		if mwConfig.CORS.Enabled {
+			app.handler = middleware.CORS(mwConfig.CORS)(app.handler)
+		}
+
+		// Wrap the router rather than using Router.Use, whose middleware
+		// only runs for matched routes, so that 404 and 405 responses are
+		// logged and get a request ID and security headers too.
+		chain := middleware.Default(mwConfig, app.Logger)
+		for i := len(chain) - 1; i >= 0; i-- {
+			app.handler = chain[i](app.handler)
+		}
+
+		if err := app.setupAuth(); err != nil {
+			return nil, app.abort(fmt.Errorf("failed to initialize auth: %w", err))
+		}
+	}
+	app.Router.Use(di.ScopeMiddleware(app.Container), registerPrincipal)
 
 	// Load routes
-	if err := app.loadRoutes(); err != nil {
-		return nil, fmt.Errorf("failed to load routes: %w", err)
+	if err := app.loadRoutes(o.routes); err != nil {
+		return nil, app.abort(fmt.Errorf("failed to load routes: %w", err))
 	}
 
 	return app, nil
 }
 
-func (a *App) Start(port string) error {
-	addr := fmt.Sprintf(":%s", port)
-	log.Printf("Server starting on %s", addr)
-	return http.ListenAndServe(addr, a.Router)
+// abort runs the stop hooks registered so far, closing the database New
+// opened, when New fails, and returns err along with any error they return.
+func (a *App) abort(err error) error {
+	return errors.Join(err, a.runStopHooks(context.Background()))
+}
+
+// setupAuth registers the authenticators enabled under auth in config.yaml
+// as "auth.jwt" and "auth.sessions", authenticates every request with them,
+// grants the permissions listed under auth.roles and enforces the
+// permissions annotated on routes.
+func (a *App) setupAuth() error {
+	tokens, sessions, err := auth.FromConfig(a.Config)
+	if err != nil {
+		return err
+	}
+
+	var authenticators []auth.Authenticator
+	if tokens != nil {
+		a.Container.Register("auth.jwt", tokens)
+		authenticators = append(authenticators, tokens)
+	}
+	if sessions != nil {
+		a.Container.Register("auth.sessions", sessions)
+		authenticators = append(authenticators, sessions)
+	}
+
+	if len(authenticators) > 0 {
+		a.Router.Use(auth.Middleware(authenticators...))
+	}
+
+	for role, permissions := range a.Config.GetStringMapStringSlice("auth.roles") {
+		a.Permissions.Grant(role, permissions...)
+	}
+	a.Router.Use(a.Permissions.Enforce)
+
+	return nil
+}
+
+// registerPrincipal registers the request's principal in its scope as
+// "principal", nil for anonymous requests, so that scoped providers can
+// depend on *auth.Principal.
+func registerPrincipal(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := auth.FromContext(r.Context())
+		di.FromContext(r.Context()).Register("principal", p)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Handler returns the handler the server runs: the router, wrapped in the
+// built-in middleware and the CORS policy when they are enabled. Use it rather than Router when serving
+// the application yourself, for example from httptest.
+func (a *App) Handler() http.Handler {
+	if a.handler == nil {
+		return a.Router
+	}
+	return a.handler
 }
 
 func (a *App) RunMigrations() error {
 	return orm.RunMigrations(a.DB)
 }
 
+// DryRunMigrations returns the DDL that RunMigrations would execute for the
+// registered models without changing the database.
+func (a *App) DryRunMigrations() ([]string, error) {
+	return orm.DryRunMigrations(a.DB)
+}
+
+// Migrator returns a migrator for the project's SQL migrations.
+func (a *App) Migrator() *orm.Migrator {
+	return orm.NewMigrator(a.DB, orm.MigrationsDir)
+}
+
 func validateProjectStructure() error {
 	requiredDirs := []string{
 		"controllers",
@@ -103,15 +279,3 @@ func validateProjectStructure() error {
 
 	return nil
 }
-
-func (a *App) loadRoutes() error {
-	// This would load routes from routes/routes.go
-	// For now, we'll add a simple health check
-	a.Router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprint(w, `{"status":"healthy","framework":"threadbolt"}`)
-	}).Methods("GET")
-
-	return nil
-}
@@ -0,0 +1,77 @@
+package framework
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ThreadBolt/threadbolt/pkg/orm"
+)
+
+// RunCommand executes a framework command ("migrate" or "migrate plan")
+// inside the application binary, where the project's registered models are
+// linked in. It reports whether args named a framework command; when it did
+// not, the caller should start the server as usual.
+func (a *App) RunCommand(args []string) (bool, error) {
+	if len(args) == 0 || args[0] != "migrate" {
+		return false, nil
+	}
+	if len(args) > 1 && args[1] == "plan" {
+		return true, a.runPlanMigrationCommand(os.Stdout)
+	}
+	return true, a.runMigrateCommand(args[1:], os.Stdout)
+}
+
+// errNoDatabase is returned by commands needing a database when
+// database.driver is "none".
+var errNoDatabase = errors.New("no database is configured; set database.driver in config/config.yaml")
+
+func (a *App) runMigrateCommand(args []string, out io.Writer) error {
+	if a.DB == nil {
+		return errNoDatabase
+	}
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "print the DDL that would be executed without running it")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if !*dryRun {
+		return a.RunMigrations()
+	}
+
+	statements, err := a.DryRunMigrations()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "-- dialect: %s\n", a.DB.Dialector.Name())
+	if len(statements) == 0 {
+		fmt.Fprintln(out, "-- schema is up to date")
+	}
+	for _, statement := range statements {
+		fmt.Fprintf(out, "%s;\n", statement)
+	}
+
+	return nil
+}
+
+// runPlanMigrationCommand writes, as JSON, the statements that reconcile the
+// database schema, including pending migrations, with the registered models
+// and the statements reverting them. "threadbolt generate migration --auto"
+// writes them to a migration, so that the application binary does not need to
+// link the generator.
+func (a *App) runPlanMigrationCommand(out io.Writer) error {
+	if a.DB == nil {
+		return errNoDatabase
+	}
+
+	plan, err := orm.NewMigrator(a.DB, orm.MigrationsDir).Plan()
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(out).Encode(plan)
+}
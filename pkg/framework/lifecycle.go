@@ -0,0 +1,150 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Hook is a lifecycle callback run when the application starts or stops.
+type Hook func(ctx context.Context) error
+
+const defaultShutdownTimeout = 30 * time.Second
+
+// OnStart registers a hook that runs before the server begins accepting
+// connections. Hooks run in the order they were registered.
+func (a *App) OnStart(hook Hook) {
+	a.hooksMutex.Lock()
+	defer a.hooksMutex.Unlock()
+	a.onStart = append(a.onStart, hook)
+}
+
+// OnStop registers a hook that runs after the server has drained its
+// connections. Hooks run in reverse registration order, so resources are
+// released in the opposite order to which they were acquired.
+func (a *App) OnStop(hook Hook) {
+	a.hooksMutex.Lock()
+	defer a.hooksMutex.Unlock()
+	a.onStop = append(a.onStop, hook)
+}
+
+// Start runs the start hooks, serves HTTP on the given port and blocks until
+// the server fails or the process receives SIGINT or SIGTERM, at which point
+// the application is shut down gracefully.
+func (a *App) Start(port string) error {
+	a.Server = &http.Server{
+		Addr:    fmt.Sprintf(":%s", port),
+		Handler: a.Handler(),
+	}
+
+	if err := a.runStartHooks(context.Background()); err != nil {
+		return err
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		a.logger().Info("server starting", "addr", a.Server.Addr)
+		if err := a.Server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+		close(serveErr)
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	select {
+	case err := <-serveErr:
+		if err != nil {
+			stopErr := a.runStopHooks(context.Background())
+			return errors.Join(fmt.Errorf("server error: %w", err), stopErr)
+		}
+		return nil
+	case sig := <-quit:
+		a.logger().Info("shutting down", "signal", sig.String(), "timeout", a.shutdownTimeout())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout())
+	defer cancel()
+
+	return a.Shutdown(ctx)
+}
+
+// Shutdown stops accepting new connections, waits for in-flight requests to
+// finish until ctx is done, and then runs the stop hooks. The hooks get
+// their own server.shutdown_timeout, as draining may have used up ctx.
+func (a *App) Shutdown(ctx context.Context) error {
+	var shutdownErr error
+	if a.Server != nil {
+		if err := a.Server.Shutdown(ctx); err != nil {
+			shutdownErr = fmt.Errorf("failed to shut down server: %w", err)
+		}
+	}
+
+	return errors.Join(shutdownErr, a.runStopHooks(ctx))
+}
+
+func (a *App) runStartHooks(ctx context.Context) error {
+	a.hooksMutex.Lock()
+	hooks := append([]Hook(nil), a.onStart...)
+	a.hooksMutex.Unlock()
+
+	for i, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			stopErr := a.runStopHooks(ctx)
+			return errors.Join(fmt.Errorf("start hook %d failed: %w", i, err), stopErr)
+		}
+	}
+
+	return nil
+}
+
+// runStopHooks runs the stop hooks with a context carrying parent's values
+// but its own shutdown timeout.
+func (a *App) runStopHooks(parent context.Context) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(parent), a.shutdownTimeout())
+	defer cancel()
+
+	a.hooksMutex.Lock()
+	hooks := a.onStop
+	a.onStop = nil
+	a.hooksMutex.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stop hook %d failed: %w", i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (a *App) shutdownTimeout() time.Duration {
+	if a.Config == nil {
+		return defaultShutdownTimeout
+	}
+
+	timeout := a.Config.GetDuration("server.shutdown_timeout")
+	if timeout <= 0 {
+		return defaultShutdownTimeout
+	}
+
+	return timeout
+}
+
+// logger returns the application's logger, falling back to the slog default
+// for an App that was not built by New.
+func (a *App) logger() *slog.Logger {
+	if a.Logger != nil {
+		return a.Logger
+	}
+	return slog.Default()
+}
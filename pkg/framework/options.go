@@ -0,0 +1,86 @@
+package framework
+
+import (
+	"log/slog"
+
+	"github.com/gorilla/mux"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+
+	"github.com/ThreadBolt/threadbolt/pkg/di"
+)
+
+// Option configures how New and LoadApp build an App.
+type Option func(*options)
+
+type options struct {
+	routes         []RouteRegistrar
+	config         *viper.Viper
+	db             *gorm.DB
+	router         *mux.Router
+	container      *di.Container
+	logger         *slog.Logger
+	skipValidation bool
+	skipMiddleware bool
+}
+
+// WithRoutes registers functions that install the application's routes. They
+// run in order once the database and container are ready.
+func WithRoutes(registrars ...RouteRegistrar) Option {
+	return func(o *options) {
+		o.routes = append(o.routes, registrars...)
+	}
+}
+
+// WithConfig uses v instead of reading config/config.yaml.
+func WithConfig(v *viper.Viper) Option {
+	return func(o *options) {
+		o.config = v
+	}
+}
+
+// WithDB uses db instead of opening the configured database.
+func WithDB(db *gorm.DB) Option {
+	return func(o *options) {
+		o.db = db
+	}
+}
+
+// WithRouter installs routes on router instead of a new mux.Router, so an
+// App can be mounted inside a larger application.
+func WithRouter(router *mux.Router) Option {
+	return func(o *options) {
+		o.router = router
+	}
+}
+
+// WithContainer uses container instead of a new di.Container.
+func WithContainer(container *di.Container) Option {
+	return func(o *options) {
+		o.container = container
+	}
+}
+
+// WithLogger uses logger instead of one built from the logging configuration.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// SkipProjectValidation disables the check that the working directory has
+// the conventional ThreadBolt project layout.
+func SkipProjectValidation() Option {
+	return func(o *options) {
+		o.skipValidation = true
+	}
+}
+
+// WithoutDefaultMiddleware leaves App.Router without the built-in middleware
+// configured under the middleware section, for applications that assemble
+// their own chain from pkg/middleware.
+func WithoutDefaultMiddleware() Option {
+	return func(o *options) {
+		o.skipMiddleware = true
+	}
+}
@@ -0,0 +1,104 @@
+package framework
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"gorm.io/gorm"
+
+	"github.com/ThreadBolt/threadbolt/pkg/auth"
+	"github.com/ThreadBolt/threadbolt/pkg/middleware"
+	"github.com/ThreadBolt/threadbolt/pkg/problem"
+	"github.com/ThreadBolt/threadbolt/pkg/query"
+	"github.com/ThreadBolt/threadbolt/pkg/validation"
+)
+
+// Problem is an RFC 7807 problem detail; see package problem.
+type Problem = problem.Problem
+
+// NewProblem returns a problem for status with a human-readable detail, for
+// handlers to pass to Error.
+func NewProblem(status int, detail string) *Problem {
+	return problem.New(status, detail)
+}
+
+// Envelope wraps every successful JSON response body.
+type Envelope struct {
+	Data interface{} `json:"data"`
+	Meta interface{} `json:"meta,omitempty"`
+}
+
+// JSON writes data as {"data": ...} with the given status.
+func JSON(w http.ResponseWriter, status int, data interface{}) {
+	writeEnvelope(w, status, Envelope{Data: data})
+}
+
+// Paginated writes a page of results as {"data": [...], "meta": {...}} with
+// a Link header pointing at the neighbouring pages.
+func Paginated(w http.ResponseWriter, r *http.Request, data interface{}, meta *query.Meta) {
+	if links := meta.Links(r.URL); links != "" {
+		w.Header().Set("Link", links)
+	}
+	writeEnvelope(w, http.StatusOK, Envelope{Data: data, Meta: meta})
+}
+
+func writeEnvelope(w http.ResponseWriter, status int, envelope Envelope) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(envelope)
+}
+
+// Error writes err as an application/problem+json response. A *Problem is
+// written as is, gorm.ErrRecordNotFound becomes a 404, validation errors a
+// 422 listing the invalid fields, and auth errors a 401 or 403. Any other
+// error is logged and answered with a 500 that does not disclose it.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	p := toProblem(err)
+	if p.Status >= http.StatusInternalServerError {
+		slog.ErrorContext(r.Context(), "request failed",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"request_id", middleware.GetRequestID(r.Context()),
+			"error", err,
+		)
+	}
+	if p.Status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+
+	response := *p
+	if response.Instance == "" {
+		response.Instance = r.URL.Path
+	}
+	if response.RequestID == "" {
+		response.RequestID = middleware.GetRequestID(r.Context())
+	}
+	problem.Write(w, &response)
+}
+
+func toProblem(err error) *Problem {
+	var p *Problem
+	var validationErrs validation.Errors
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &p):
+		return p
+	case errors.As(err, &validationErrs):
+		return validation.Problem(validationErrs)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return problem.New(http.StatusNotFound, "resource not found")
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return problem.New(http.StatusConflict, "resource already exists")
+	case errors.Is(err, auth.ErrUnauthenticated):
+		return problem.New(http.StatusUnauthorized, "authentication required")
+	case errors.Is(err, auth.ErrForbidden):
+		return problem.New(http.StatusForbidden, "you are not allowed to perform this action")
+	case errors.As(err, &maxBytesErr):
+		return problem.New(http.StatusRequestEntityTooLarge, "request body too large")
+	default:
+		return problem.New(http.StatusInternalServerError, "")
+	}
+}
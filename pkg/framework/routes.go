@@ -0,0 +1,44 @@
+package framework
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// RouteRegistrar installs routes on app.Router. The SetupRoutes function in a
+// generated project's routes package is a RouteRegistrar.
+type RouteRegistrar func(app *App)
+
+const healthPath = "/health"
+
+func (a *App) loadRoutes(registrars []RouteRegistrar) error {
+	for _, register := range registrars {
+		register(a)
+	}
+
+	// Only add the built-in health check when the application hasn't
+	// defined its own.
+	if !a.hasRoute(http.MethodGet, healthPath) {
+		a.Router.HandleFunc(healthPath, healthCheck).Methods(http.MethodGet)
+	}
+
+	return nil
+}
+
+func (a *App) hasRoute(method, path string) bool {
+	req, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		return false
+	}
+
+	var match mux.RouteMatch
+	return a.Router.Match(req, &match) && match.MatchErr == nil
+}
+
+func healthCheck(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, `{"status":"healthy","framework":"threadbolt"}`)
+}
@@ -0,0 +1,113 @@
+package generator
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// GenerateAuth scaffolds a User model, an AuthController with register,
+// login, refresh, logout and me handlers, and an AuthRoutes registrar. It
+// also enables JWT and session auth in config/config.yaml and writes their
+// secrets to .env unless either is already set up.
+func GenerateAuth() error {
+	appName, err := modulePath()
+	if err != nil {
+		return err
+	}
+
+	data := struct {
+		AppName string
+	}{
+		AppName: appName,
+	}
+
+	files := []struct {
+		path     string
+		template string
+	}{
+		{"models/user.go", "auth/user"},
+		{"controllers/auth_controller.go", "auth/controller"},
+		{"routes/auth.go", "auth/routes"},
+	}
+
+	return Transaction(func() error {
+		for _, file := range files {
+			template, err := loadTemplate(file.template)
+			if err != nil {
+				return err
+			}
+			if err := generateFile(file.path, template, data); err != nil {
+				return fmt.Errorf("failed to generate %s: %w", file.path, err)
+			}
+		}
+
+		if err := UpdateModelRegistry(); err != nil {
+			return err
+		}
+		if err := appendAuthConfig(data); err != nil {
+			return err
+		}
+		return appendSecrets("JWT_SECRET", "SESSION_SECRET")
+	})
+}
+
+var authSection = regexp.MustCompile(`(?m)^auth:`)
+
+func appendAuthConfig(data interface{}) error {
+	const configFile = "config/config.yaml"
+
+	existing, err := os.ReadFile(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", configFile, err)
+	}
+	if authSection.Match(existing) {
+		return nil
+	}
+
+	template, err := loadTemplate("auth/config")
+	if err != nil {
+		return err
+	}
+	config, err := renderFile(configFile, template, data)
+	if err != nil {
+		return err
+	}
+	return writeFile(configFile, append(existing, config...))
+}
+
+// appendSecrets adds random values for the named variables, such as the
+// JWT_SECRET and SESSION_SECRET the auth config refers to, to .env, which is
+// git-ignored. Variables already set there are left alone.
+func appendSecrets(names ...string) error {
+	existing, err := os.ReadFile(".env")
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	var lines []string
+	for _, name := range names {
+		if regexp.MustCompile(`(?m)^` + name + `=`).Match(existing) {
+			continue
+		}
+
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return fmt.Errorf("failed to generate secret: %w", err)
+		}
+		lines = append(lines, name+"="+hex.EncodeToString(secret))
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	content := strings.Join(lines, "\n") + "\n"
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		content = "\n" + content
+	}
+
+	return writeFileMode(".env", append(existing, content...), 0600)
+}
@@ -2,132 +2,95 @@ package generator
 
 import (
 	"fmt"
+	"os"
 	"strings"
 )
 
+// GenerateController writes controllers/<name>_controller.go with CRUD
+// handlers for the model of the same name, which must exist; see
+// ErrModelNotFound. The controller's routes are registered in SetupRoutes if
+// the project has a routes/routes.go.
 func GenerateController(controllerName string) error {
-	fileName := fmt.Sprintf("controllers/%s_controller.go", strings.ToLower(controllerName))
-	
-	template := `package controllers
-
-import (
-	"encoding/json"
-	"net/http"
-	"strconv"
-
-	"github.com/gorilla/mux"
-	"gorm.io/gorm"
-	
-	"{{.AppName}}/models"
-)
-
-type {{.ControllerName}}Controller struct {
-	repo *models.{{.ControllerName}}Repository
-}
-
-func New{{.ControllerName}}Controller(db *gorm.DB) *{{.ControllerName}}Controller {
-	return &{{.ControllerName}}Controller{
-		repo: models.New{{.ControllerName}}Repository(db),
-	}
-}
-
-// Create{{.ControllerName}} handles POST /{{.ControllerNameLower}}s
-func (c *{{.ControllerName}}Controller) Create{{.ControllerName}}(w http.ResponseWriter, r *http.Request) {
-	var {{.ControllerNameLower}} models.{{.ControllerName}}
-	
-	if err := json.NewDecoder(r.Body).Decode(&{{.ControllerNameLower}}); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
-		return
+	module, err := modulePath()
+	if err != nil {
+		return err
 	}
-
-	if err := c.repo.Create(&{{.ControllerNameLower}}); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	if err := checkModel(controllerName); err != nil {
+		return err
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode({{.ControllerNameLower}})
+	return Transaction(func() error {
+		if err := generateController(controllerName, module); err != nil {
+			return err
+		}
+		if _, err := os.Stat(routesFile); os.IsNotExist(err) {
+			return nil
+		}
+		return addResourceRoutes(controllerName, module)
+	})
 }
 
-// Get{{.ControllerName}} handles GET /{{.ControllerNameLower}}s/{id}
-func (c *{{.ControllerName}}Controller) Get{{.ControllerName}}(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.ParseUint(vars["id"], 10, 32)
+// DestroyController undoes GenerateController: it removes the controller's
+// routes from SetupRoutes, the controller and the tests generated for it by
+// GenerateScaffold, and returns the files changed. The model is kept.
+func DestroyController(controllerName string) ([]string, error) {
+	module, err := modulePath()
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
-		return
+		return nil, err
 	}
 
-	{{.ControllerNameLower}}, err := c.repo.GetByID(uint(id))
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			http.Error(w, "{{.ControllerName}} not found", http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	var changed []string
+	err = Transaction(func() error {
+		removed, err := removeResourceRoutes(controllerName, module)
+		if err != nil {
+			return err
+		}
+		if removed {
+			changed = append(changed, routesFile)
 		}
-		return
-	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode({{.ControllerNameLower}})
-}
+		for _, fileName := range []string{
+			controllerFileName(controllerName),
+			controllerTestFileName(controllerName),
+		} {
+			if _, err := os.Stat(fileName); os.IsNotExist(err) {
+				continue
+			}
+			if err := removeFile(fileName); err != nil {
+				return err
+			}
+			changed = append(changed, fileName)
+		}
 
-// GetAll{{.ControllerName}}s handles GET /{{.ControllerNameLower}}s
-func (c *{{.ControllerName}}Controller) GetAll{{.ControllerName}}s(w http.ResponseWriter, r *http.Request) {
-	{{.ControllerNameLower}}s, err := c.repo.GetAll()
+		if len(changed) == 0 {
+			return fmt.Errorf("controller %s does not exist", controllerName)
+		}
+		return nil
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return nil, err
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode({{.ControllerNameLower}}s)
+	return changed, nil
 }
 
-// Update{{.ControllerName}} handles PUT /{{.ControllerNameLower}}s/{id}
-func (c *{{.ControllerName}}Controller) Update{{.ControllerName}}(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.ParseUint(vars["id"], 10, 32)
-	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
-		return
-	}
-
-	var {{.ControllerNameLower}} models.{{.ControllerName}}
-	if err := json.NewDecoder(r.Body).Decode(&{{.ControllerNameLower}}); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
-		return
-	}
-
-	{{.ControllerNameLower}}.ID = uint(id)
-	if err := c.repo.Update(&{{.ControllerNameLower}}); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+func controllerFileName(controllerName string) string {
+	return fmt.Sprintf("controllers/%s_controller.go", strings.ToLower(controllerName))
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode({{.ControllerNameLower}})
+func controllerTestFileName(controllerName string) string {
+	return fmt.Sprintf("tests/%s_controller_test.go", strings.ToLower(controllerName))
 }
 
-// Delete{{.ControllerName}} handles DELETE /{{.ControllerNameLower}}s/{id}
-func (c *{{.ControllerName}}Controller) Delete{{.ControllerName}}(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.ParseUint(vars["id"], 10, 32)
+// generateController writes the CRUD controller for a model, importing the
+// models package of the module at modulePath.
+func generateController(controllerName, modulePath string) error {
+	fileName := controllerFileName(controllerName)
+	
+	template, err := loadTemplate("controller")
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
-		return
+		return err
 	}
 
-	if err := c.repo.Delete(uint(id)); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.WriteHeader(http.StatusNoContent)
-}
-`
-
 	data := struct {
 		ControllerName      string
 		ControllerNameLower string
@@ -135,8 +98,17 @@ func (c *{{.ControllerName}}Controller) Delete{{.ControllerName}}(w http.Respons
 	}{
 		ControllerName:      controllerName,
 		ControllerNameLower: strings.ToLower(controllerName),
-		AppName:             "example-app", // This should be dynamic
+		AppName:             modulePath,
+	}
+
+	if err := generateFile(fileName, template, data); err != nil {
+		return err
 	}
 
-	return generateFile(fileName, template, data)
+	// The controller binds requests to the model's DTOs, which older models
+	// may not have yet.
+	if _, err := os.Stat(requestsFileName(controllerName)); os.IsNotExist(err) {
+		return generateRequests(controllerName, defaultFields)
+	}
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,287 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Field is a model field parsed from a "name:type[:modifier...]" argument
+// such as "email:string:unique", "price:decimal{10,2}" or "team:references".
+type Field struct {
+	// Name is the Go field name and Column the column and JSON name, such
+	// as "TeamID" and "team_id".
+	Name   string
+	Column string
+	// Kind is the field's type as given, such as "string" or "references".
+	Kind      string
+	Size      int
+	Precision int
+	Scale     int
+	Nullable  bool
+	Unique    bool
+	Index     bool
+	Default   string
+	// Association and Reference are the association field and the model it
+	// refers to for references fields, such as "Team" and "Team".
+	Association string
+	Reference   string
+}
+
+var goTypes = map[string]string{
+	"string":     "string",
+	"text":       "string",
+	"uuid":       "string",
+	"int":        "int",
+	"bigint":     "int64",
+	"uint":       "uint",
+	"float":      "float64",
+	"decimal":    "float64",
+	"bool":       "bool",
+	"time":       "time.Time",
+	"date":       "time.Time",
+	"references": "uint",
+}
+
+var typeAliases = map[string]string{
+	"integer":    "int",
+	"int64":      "bigint",
+	"float64":    "float",
+	"boolean":    "bool",
+	"datetime":   "time",
+	"timestamp":  "time",
+	"belongs_to": "references",
+}
+
+var baseModelColumns = map[string]bool{"id": true, "created_at": true, "updated_at": true, "deleted_at": true}
+
+var (
+	fieldName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+	fieldType = regexp.MustCompile(`^([a-z_0-9]+)(?:\{([^}]*)\})?$`)
+)
+
+// ParseFields parses "name:type[:modifier...]" field arguments. Types are
+// string{size}, text, uuid, int, bigint, uint, float, decimal{precision,scale},
+// bool, time, date and references{Model}; modifiers are unique, index, null
+// and default=value.
+func ParseFields(args []string) ([]Field, error) {
+	var fields []Field
+	seen := make(map[string]bool)
+
+	for _, arg := range args {
+		field, err := parseField(arg)
+		if err != nil {
+			return nil, err
+		}
+		if seen[field.Column] {
+			return nil, fmt.Errorf("invalid field %q: %s is defined twice", arg, field.Column)
+		}
+		seen[field.Column] = true
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+func parseField(arg string) (Field, error) {
+	parts := strings.Split(arg, ":")
+	if len(parts) < 2 || !fieldName.MatchString(parts[0]) {
+		return Field{}, fmt.Errorf("invalid field %q: expected name:type[:modifier...]", arg)
+	}
+
+	match := fieldType.FindStringSubmatch(parts[1])
+	if match == nil {
+		return Field{}, fmt.Errorf("invalid field %q: malformed type %q", arg, parts[1])
+	}
+	kind, param := match[1], match[2]
+	if alias, ok := typeAliases[kind]; ok {
+		kind = alias
+	}
+	if _, ok := goTypes[kind]; !ok {
+		return Field{}, fmt.Errorf("invalid field %q: unknown type %q", arg, kind)
+	}
+
+	field := Field{Kind: kind, Column: toSnakeCase(parts[0])}
+	if kind == "references" {
+		field.Association = toPascalCase(parts[0])
+		field.Reference = field.Association
+		if param != "" {
+			field.Reference = toPascalCase(param)
+		}
+		field.Column = strings.TrimSuffix(field.Column, "_id") + "_id"
+		field.Index = true
+	}
+	field.Name = toPascalCase(field.Column)
+	if baseModelColumns[field.Column] {
+		return Field{}, fmt.Errorf("invalid field %q: %s is provided by BaseModel", arg, field.Column)
+	}
+
+	var err error
+	switch {
+	case kind == "string":
+		field.Size = 255
+		if param != "" {
+			field.Size, err = strconv.Atoi(param)
+		}
+	case kind == "decimal":
+		field.Precision, field.Scale = 10, 2
+		if param != "" {
+			_, err = fmt.Sscanf(param, "%d,%d", &field.Precision, &field.Scale)
+		}
+	case param != "" && kind != "references":
+		err = fmt.Errorf("%s takes no parameters", kind)
+	}
+	if err != nil {
+		return Field{}, fmt.Errorf("invalid field %q: bad parameters %q for %s", arg, param, kind)
+	}
+
+	for _, modifier := range parts[2:] {
+		switch {
+		case modifier == "unique":
+			field.Unique = true
+		case modifier == "index":
+			field.Index = true
+		case modifier == "null":
+			field.Nullable = true
+		case strings.HasPrefix(modifier, "default="):
+			field.Default = strings.TrimPrefix(modifier, "default=")
+		default:
+			return Field{}, fmt.Errorf("invalid field %q: unknown modifier %q", arg, modifier)
+		}
+	}
+
+	return field, nil
+}
+
+// Pointer reports whether the field is a pointer in the model, as nullable
+// fields are and fields with a default need to be, for GORM to tell a zero
+// value from one left unset.
+func (f Field) Pointer() bool {
+	return f.Nullable || f.Default != ""
+}
+
+// GoType is the type of the field in the model.
+func (f Field) GoType() string {
+	if f.Pointer() {
+		return "*" + goTypes[f.Kind]
+	}
+	return goTypes[f.Kind]
+}
+
+// ValueType is the field's type without the pointer of nullable fields.
+func (f Field) ValueType() string {
+	return goTypes[f.Kind]
+}
+
+// Tag is the field's struct tag in the model.
+func (f Field) Tag() string {
+	var gorm []string
+	switch f.Kind {
+	case "string":
+		gorm = append(gorm, fmt.Sprintf("size:%d", f.Size))
+	case "text":
+		gorm = append(gorm, "type:text")
+	case "uuid":
+		gorm = append(gorm, "size:36")
+	case "decimal":
+		gorm = append(gorm, fmt.Sprintf("type:decimal(%d,%d)", f.Precision, f.Scale))
+	case "date":
+		gorm = append(gorm, "type:date")
+	}
+	if !f.Nullable {
+		gorm = append(gorm, "not null")
+	}
+	if f.Default != "" {
+		gorm = append(gorm, "default:"+f.Default)
+	}
+	if f.Unique {
+		gorm = append(gorm, "uniqueIndex")
+	} else if f.Index {
+		gorm = append(gorm, "index")
+	}
+
+	if len(gorm) == 0 {
+		return fmt.Sprintf("`json:\"%s\"`", f.Column)
+	}
+	return fmt.Sprintf("`gorm:\"%s\" json:\"%s\"`", strings.Join(gorm, ";"), f.Column)
+}
+
+// AssociationTag is the struct tag of a references field's association.
+func (f Field) AssociationTag() string {
+	column := strings.TrimSuffix(f.Column, "_id")
+	if f.Nullable {
+		return fmt.Sprintf("`gorm:\"constraint:OnDelete:SET NULL\" json:\"%s,omitempty\"`", column)
+	}
+	return fmt.Sprintf("`json:\"%s,omitempty\"`", column)
+}
+
+// CreateRules and UpdateRules are the validation rules of the field in the
+// create and update request DTOs.
+func (f Field) CreateRules() string {
+	var rules []string
+	if !f.Nullable && f.Default == "" && (f.Kind == "string" || f.Kind == "text" || f.Kind == "uuid" || f.Kind == "references") {
+		rules = append(rules, "required")
+	}
+	return strings.Join(append(rules, f.valueRules()...), ",")
+}
+
+func (f Field) UpdateRules() string {
+	var rules []string
+	if !f.Nullable && (f.Kind == "string" || f.Kind == "text" || f.Kind == "references") {
+		rules = append(rules, "min=1")
+	}
+	return strings.Join(append(rules, f.valueRules()...), ",")
+}
+
+func (f Field) valueRules() []string {
+	switch f.Kind {
+	case "string":
+		return []string{fmt.Sprintf("max=%d", f.Size)}
+	case "uuid":
+		return []string{"uuid"}
+	}
+	return nil
+}
+
+// Sortable reports whether lists may be sorted by the field.
+func (f Field) Sortable() bool {
+	return f.Kind != "text"
+}
+
+// Sample returns a valid JSON value for the field, used in generated tests.
+func (f Field) Sample() string {
+	switch f.Kind {
+	case "string":
+		sample := "example"
+		if f.Size < len(sample) {
+			sample = sample[:f.Size]
+		}
+		return fmt.Sprintf("%q", sample)
+	case "text":
+		return `"example text"`
+	case "uuid":
+		return `"8f14e45f-ceea-467f-a8e6-4d5b5f4c1d2a"`
+	case "float", "decimal":
+		return "1.5"
+	case "bool":
+		return "true"
+	case "time", "date":
+		return `"2024-01-02T00:00:00Z"`
+	default:
+		return "1"
+	}
+}
+
+// defaultFields are the fields of models generated without field arguments.
+var defaultFields = []Field{{Name: "Name", Column: "name", Kind: "string", Size: 255}}
+
+// usesTime reports whether any of fields is a time.Time.
+func usesTime(fields []Field) bool {
+	for _, field := range fields {
+		if field.ValueType() == "time.Time" {
+			return true
+		}
+	}
+	return false
+}
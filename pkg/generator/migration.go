@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/ThreadBolt/threadbolt/pkg/orm"
+)
+
+// GenerateMigration writes a timestamped pair of up and down SQL files to the
+// migrations directory and returns their paths. The statements may be empty,
+// in which case the files contain only a header for the migration to be
+// written by hand; with --auto, they are planned by the project's
+// "migrate plan" command.
+func GenerateMigration(name string, up, down []string) ([]string, error) {
+	version, err := orm.NextVersion(orm.MigrationsDir)
+	if err != nil {
+		return nil, err
+	}
+	base := filepath.Join(orm.MigrationsDir, fmt.Sprintf("%s_%s", version, toSnakeCase(name)))
+
+	files := []struct {
+		path       string
+		statements []string
+	}{
+		{base + ".up.sql", up},
+		{base + ".down.sql", down},
+	}
+
+	template, err := loadTemplate("migration")
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, file := range files {
+		data := struct {
+			Name       string
+			Version    string
+			Statements []string
+		}{
+			Name:       name,
+			Version:    version,
+			Statements: file.statements,
+		}
+
+		if err := generateFile(file.path, template, data); err != nil {
+			return paths, err
+		}
+		paths = append(paths, file.path)
+	}
+
+	return paths, nil
+}
@@ -5,71 +5,96 @@ import (
 	"strings"
 )
 
-func GenerateModel(modelName string) error {
+// GenerateModel writes models/<name>.go with the given fields, a repository
+// and request DTOs for it, and registers the model. Without fields the model
+// gets a single Name string.
+func GenerateModel(modelName string, fields []Field) error {
 	fileName := fmt.Sprintf("models/%s.go", strings.ToLower(modelName))
 
-	template := `package models
-
-import (
-	"gorm.io/gorm"
-)
-
-type {{.ModelName}} struct {
-	BaseModel
-	Name string ` + "`gorm:\"not null\" json:\"name\"`" + `
-	// Add your fields here
-}
+	if len(fields) == 0 {
+		fields = defaultFields
+	}
+	if err := checkReferences(modelName, fields); err != nil {
+		return err
+	}
 
-// {{.ModelName}}Repository provides data access methods for {{.ModelName}}
-type {{.ModelName}}Repository struct {
-	db *gorm.DB
-}
+	template, err := loadTemplate("model")
+	if err != nil {
+		return err
+	}
 
-// New{{.ModelName}}Repository creates a new repository instance
-func New{{.ModelName}}Repository(db *gorm.DB) *{{.ModelName}}Repository {
-	return &{{.ModelName}}Repository{db: db}
-}
+	data := struct {
+		ModelName      string
+		ModelNameLower string
+		Fields         []Field
+		UsesTime       bool
+	}{
+		ModelName:      modelName,
+		ModelNameLower: strings.ToLower(modelName),
+		Fields:         fields,
+		UsesTime:       usesTime(fields),
+	}
 
-// Create creates a new {{.ModelName}}
-func (r *{{.ModelName}}Repository) Create({{.ModelNameLower}} *{{.ModelName}}) error {
-	return r.db.Create({{.ModelNameLower}}).Error
+	return Transaction(func() error {
+		if err := generateFile(fileName, template, data); err != nil {
+			return err
+		}
+		if err := generateRequests(modelName, fields); err != nil {
+			return err
+		}
+		return UpdateModelRegistry()
+	})
 }
 
-// GetByID retrieves a {{.ModelName}} by ID
-func (r *{{.ModelName}}Repository) GetByID(id uint) (*{{.ModelName}}, error) {
-	var {{.ModelNameLower}} {{.ModelName}}
-	err := r.db.First(&{{.ModelNameLower}}, id).Error
+// checkReferences fails if a references field names a model that does not
+// exist yet, as the generated model would not compile.
+func checkReferences(modelName string, fields []Field) error {
+	models, err := findModels("models")
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return &{{.ModelNameLower}}, nil
-}
 
-// GetAll retrieves all {{.ModelName}}s
-func (r *{{.ModelName}}Repository) GetAll() ([]{{.ModelName}}, error) {
-	var {{.ModelNameLower}}s []{{.ModelName}}
-	err := r.db.Find(&{{.ModelNameLower}}s).Error
-	return {{.ModelNameLower}}s, err
+	for _, field := range fields {
+		if field.Reference == "" || field.Reference == modelName || contains(models, field.Reference) {
+			continue
+		}
+		return fmt.Errorf("model %s referenced by %s does not exist; generate it first", field.Reference, field.Column)
+	}
+	return nil
 }
 
-// Update updates a {{.ModelName}}
-func (r *{{.ModelName}}Repository) Update({{.ModelNameLower}} *{{.ModelName}}) error {
-	return r.db.Save({{.ModelNameLower}}).Error
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
 }
 
-// Delete deletes a {{.ModelName}}
-func (r *{{.ModelName}}Repository) Delete(id uint) error {
-	return r.db.Delete(&{{.ModelName}}{}, id).Error
+func requestsFileName(modelName string) string {
+	return fmt.Sprintf("models/%s_requests.go", strings.ToLower(modelName))
 }
-`
+
+// generateRequests writes the validated request DTOs that generated
+// controllers bind create and update requests to.
+func generateRequests(modelName string, fields []Field) error {
+	template, err := loadTemplate("requests")
+	if err != nil {
+		return err
+	}
 
 	data := struct {
 		ModelName      string
 		ModelNameLower string
+		Fields         []Field
+		UsesTime       bool
 	}{
 		ModelName:      modelName,
 		ModelNameLower: strings.ToLower(modelName),
+		Fields:         fields,
+		UsesTime:       usesTime(fields),
 	}
 
-	return generateFile(fileName, template, data)
+	return generateFile(requestsFileName(modelName), template, data)
 }
@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"strings"
+	"unicode"
+)
+
+// toSnakeCase converts names such as "AddEmailToUsers" or "add-email" to
+// "add_email_to_users" and "add_email".
+func toSnakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(strings.TrimSpace(name))
+
+	for i, r := range runes {
+		switch {
+		case r == '-' || r == ' ' || r == '.':
+			b.WriteRune('_')
+		case unicode.IsUpper(r):
+			if i > 0 && runes[i-1] != '_' && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// commonInitialisms are written in upper case in Go names, as golint does.
+var commonInitialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "ID": true, "IP": true, "JSON": true,
+	"SQL": true, "URL": true, "URI": true, "UUID": true,
+}
+
+// toPascalCase converts names such as "team_id" or "first-name" to "TeamID"
+// and "FirstName".
+func toPascalCase(name string) string {
+	var b strings.Builder
+	words := strings.FieldsFunc(toSnakeCase(name), func(r rune) bool { return r == '_' })
+
+	for _, word := range words {
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+
+	return b.String()
+}
+
+// toCamelCase converts names such as "team_id" or "ID" to "teamID" and "id".
+func toCamelCase(name string) string {
+	words := strings.FieldsFunc(toSnakeCase(name), func(r rune) bool { return r == '_' })
+	if len(words) == 0 {
+		return ""
+	}
+	first := toPascalCase(words[0])
+	return strings.ToLower(first) + toPascalCase(name)[len(first):]
+}
@@ -1,13 +1,113 @@
 package generator
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"text/template"
 )
 
-func CreateNewProject(appName string) error {
+// ProjectOptions configure CreateNewProject.
+type ProjectOptions struct {
+	// Template is a built-in template, "api", "web" or "minimal", or the
+	// directory of a custom template such as a git checkout. It defaults to
+	// "api".
+	Template string
+	// Vars are values for the custom template's variables. Prompt is asked
+	// for the others; without it, they take their defaults.
+	Vars   map[string]string
+	Prompt func(variable TemplateVariable) (string, error)
+}
+
+// projectTemplate is the layout of a new project.
+type projectTemplate struct {
+	// dirs are created even if no file is generated in them.
+	dirs []string
+	// files are text/template templates rendered with the app name and
+	// template variables, and static files are copied as they are.
+	files  map[string]string
+	static map[string]string
+	// secrets are written to .env with random values.
+	secrets   []string
+	variables []TemplateVariable
+}
+
+var projectTemplates = map[string]projectTemplate{
+	"api": {
+		dirs: []string{"cmd", "config", "controllers", "internal/middleware", "internal/services", "models", "migrations", "public", "routes", "templates", "tests"},
+		files: map[string]string{
+			"main.go":                           builtin("project/main.tmpl"),
+			"go.mod":                            builtin("project/go_mod.tmpl"),
+			"config/config.yaml":                builtin("project/config.tmpl"),
+			"routes/routes.go":                  builtin("project/routes.tmpl"),
+			"controllers/health_controller.go":  builtin("project/health_controller.tmpl"),
+			"models/base.go":                    builtin("project/base_model.tmpl"),
+			"models/registry.go":                builtin("registry.tmpl"),
+			"internal/middleware/middleware.go": builtin("project/middleware.tmpl"),
+			".gitignore":                        builtin("project/gitignore.tmpl"),
+			"README.md":                         builtin("project/readme.tmpl"),
+		},
+	},
+	"web": {
+		dirs: []string{"cmd", "config", "controllers", "internal/middleware", "internal/services", "models", "migrations", "public", "routes", "templates", "tests"},
+		files: map[string]string{
+			"main.go":                           builtin("project/main.tmpl"),
+			"go.mod":                            builtin("project/go_mod.tmpl"),
+			"config/config.yaml":                builtin("project/config.tmpl"),
+			"routes/routes.go":                  builtin("project/web_routes.tmpl"),
+			"controllers/health_controller.go":  builtin("project/health_controller.tmpl"),
+			"controllers/page_controller.go":    builtin("project/page_controller.tmpl"),
+			"models/base.go":                    builtin("project/base_model.tmpl"),
+			"models/registry.go":                builtin("registry.tmpl"),
+			"internal/middleware/middleware.go": builtin("project/middleware.tmpl"),
+			".gitignore":                        builtin("project/gitignore.tmpl"),
+			"README.md":                         builtin("project/readme.tmpl"),
+		},
+		static: map[string]string{
+			"templates/layouts/app.html":  builtin("project/web/templates/layouts/app.html"),
+			"templates/partials/nav.html": builtin("project/web/templates/partials/nav.html"),
+			"templates/pages/home.html":   builtin("project/web/templates/pages/home.html"),
+			"public/css/app.css":          builtin("project/web/public/css/app.css"),
+		},
+		secrets: []string{"SESSION_SECRET"},
+	},
+	"minimal": {
+		dirs: []string{"config", "controllers", "routes"},
+		files: map[string]string{
+			"main.go":                          builtin("project/main.tmpl"),
+			"go.mod":                           builtin("project/go_mod.tmpl"),
+			"config/config.yaml":               builtin("project/config.tmpl"),
+			"routes/routes.go":                 builtin("project/minimal_routes.tmpl"),
+			"controllers/health_controller.go": builtin("project/health_controller.tmpl"),
+			".gitignore":                       builtin("project/gitignore.tmpl"),
+			"README.md":                        builtin("project/readme.tmpl"),
+		},
+	},
+}
+
+// CreateNewProject creates the directory appName from a project template and
+// changes into it.
+func CreateNewProject(appName string, opts ProjectOptions) error {
+	if opts.Template == "" {
+		opts.Template = "api"
+	}
+
+	// A custom template is read, and its variables resolved, before the
+	// project directory is created, as its path may be relative.
+	tmpl, ok := projectTemplates[opts.Template]
+	if !ok {
+		var err error
+		if tmpl, err = loadProjectTemplate(opts.Template); err != nil {
+			return err
+		}
+	}
+	data, err := templateData(appName, opts, tmpl.variables)
+	if err != nil {
+		return err
+	}
+
 	// Create project directory
 	if err := os.MkdirAll(appName, 0755); err != nil {
 		return fmt.Errorf("failed to create project directory: %w", err)
@@ -19,75 +119,64 @@ func CreateNewProject(appName string) error {
 	}
 
 	// Create directory structure
-	dirs := []string{
-		"cmd",
-		"config",
-		"controllers",
-		"internal/middleware",
-		"internal/services",
-		"models",
-		"migrations",
-		"public",
-		"routes",
-		"templates",
-		"tests",
-	}
-
-	for _, dir := range dirs {
+	for _, dir := range tmpl.dirs {
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			return fmt.Errorf("failed to create directory %s: %w", dir, err)
 		}
 	}
 
-	// Generate files
-	files := map[string]string{
-		"main.go":                    mainGoTemplate,
-		"go.mod":                     goModTemplate,
-		"config/config.yaml":         configTemplate,
-		"routes/routes.go":           routesTemplate,
-		"controllers/health_controller.go": healthControllerTemplate,
-		"models/base.go":             baseModelTemplate,
-		"internal/middleware/cors.go": corsMiddlewareTemplate,
-		".gitignore":                 gitignoreTemplate,
-		"README.md":                  readmeTemplate,
-	}
-
-	data := struct {
-		AppName string
-	}{
-		AppName: appName,
+	// go.mod comes first, as formatting the Go files groups imports by the
+	// module path.
+	paths := make([]string, 0, len(tmpl.files))
+	for filePath := range tmpl.files {
+		paths = append(paths, filePath)
 	}
+	sort.Slice(paths, func(i, j int) bool {
+		return paths[i] == "go.mod" || (paths[j] != "go.mod" && paths[i] < paths[j])
+	})
 
-	for filePath, templateContent := range files {
-		if err := generateFile(filePath, templateContent, data); err != nil {
-			return fmt.Errorf("failed to generate %s: %w", filePath, err)
+	return Transaction(func() error {
+		for _, filePath := range paths {
+			if err := generateFile(filePath, tmpl.files[filePath], data); err != nil {
+				return fmt.Errorf("failed to generate %s: %w", filePath, err)
+			}
 		}
-	}
-
-	return nil
+		for filePath, content := range tmpl.static {
+			if err := createFile(filePath, []byte(content)); err != nil {
+				return err
+			}
+		}
+		if len(tmpl.secrets) > 0 {
+			return appendSecrets(tmpl.secrets...)
+		}
+		return nil
+	})
 }
 
+// generateFile renders a template into filePath; see createFile for what
+// happens when the file already exists.
 func generateFile(filePath, templateContent string, data interface{}) error {
-	// Create directory if it doesn't exist
-	dir := filepath.Dir(filePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", dir, err)
-	}
-
-	tmpl, err := template.New(filePath).Parse(templateContent)
+	content, err := renderFile(filePath, templateContent, data)
 	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+		return err
 	}
+	return createFile(filePath, content)
+}
 
-	file, err := os.Create(filePath)
+// renderFile executes a template for filePath, formatting Go files.
+func renderFile(filePath, templateContent string, data interface{}) ([]byte, error) {
+	tmpl, err := template.New(filePath).Funcs(templateFuncs).Parse(templateContent)
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return nil, fmt.Errorf("failed to parse template: %w", err)
 	}
-	defer file.Close()
 
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
 	}
 
-	return nil
-}
\ No newline at end of file
+	if filepath.Ext(filePath) == ".go" {
+		return formatSource(filePath, buf.Bytes())
+	}
+	return buf.Bytes(), nil
+}
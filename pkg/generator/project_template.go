@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// templateManifest is the file describing a custom project template.
+const templateManifest = "template.yaml"
+
+// TemplateVariable is a variable declared in a custom project template's
+// manifest, available to its templates as {{.Name}}.
+type TemplateVariable struct {
+	Name    string `mapstructure:"name"`
+	Prompt  string `mapstructure:"prompt"`
+	Default string `mapstructure:"default"`
+	// Choices, if any, are the values the variable may take.
+	Choices []string `mapstructure:"choices"`
+}
+
+// loadProjectTemplate reads a custom project template from dir. Files ending
+// in .tmpl are rendered, without the suffix, and the others are copied. The
+// optional template.yaml manifest declares variables and the empty
+// directories to create:
+//
+//	variables:
+//	  - name: Port
+//	    prompt: HTTP port
+//	    default: "8080"
+//	dirs: [migrations]
+func loadProjectTemplate(dir string) (projectTemplate, error) {
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return projectTemplate{}, fmt.Errorf("unknown template %q: use api, web, minimal or the path of a template directory", dir)
+	}
+
+	tmpl := projectTemplate{files: make(map[string]string), static: make(map[string]string)}
+
+	manifest := filepath.Join(dir, templateManifest)
+	if _, err := os.Stat(manifest); err == nil {
+		v := viper.New()
+		v.SetConfigFile(manifest)
+		if err := v.ReadInConfig(); err != nil {
+			return projectTemplate{}, fmt.Errorf("failed to read %s: %w", manifest, err)
+		}
+		if err := v.UnmarshalKey("variables", &tmpl.variables); err != nil {
+			return projectTemplate{}, fmt.Errorf("failed to read variables from %s: %w", manifest, err)
+		}
+		tmpl.dirs = v.GetStringSlice("dirs")
+	}
+
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if path == manifest {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+		if name, ok := strings.CutSuffix(rel, ".tmpl"); ok {
+			tmpl.files[name] = string(content)
+		} else {
+			tmpl.static[rel] = string(content)
+		}
+		return nil
+	})
+	if err != nil {
+		return projectTemplate{}, fmt.Errorf("failed to read template %s: %w", dir, err)
+	}
+
+	return tmpl, nil
+}
+
+// templateData returns the data project templates are rendered with: the
+// app name, the template and the values of the template's variables.
+func templateData(appName string, opts ProjectOptions, variables []TemplateVariable) (map[string]interface{}, error) {
+	data := map[string]interface{}{
+		"AppName":  appName,
+		"Template": opts.Template,
+		"Models":   []string(nil),
+	}
+
+	declared := make(map[string]bool)
+	for _, variable := range variables {
+		declared[variable.Name] = true
+
+		value, ok := opts.Vars[variable.Name]
+		if !ok && opts.Prompt != nil {
+			var err error
+			if value, err = opts.Prompt(variable); err != nil {
+				return nil, err
+			}
+		}
+		if value == "" {
+			value = variable.Default
+		}
+
+		if value == "" {
+			return nil, fmt.Errorf("template variable %s is required; set it with --var %s=value", variable.Name, variable.Name)
+		}
+		if len(variable.Choices) > 0 && !contains(variable.Choices, value) {
+			return nil, fmt.Errorf("invalid value %q for template variable %s: must be one of %s", value, variable.Name, strings.Join(variable.Choices, ", "))
+		}
+		data[variable.Name] = value
+	}
+
+	var unknown []string
+	for name := range opts.Vars {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("template %s has no variables named %s", opts.Template, strings.Join(unknown, ", "))
+	}
+
+	return data, nil
+}
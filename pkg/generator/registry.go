@@ -0,0 +1,179 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const modelRegistryFile = "models/registry.go"
+
+// UpdateModelRegistry regenerates models/registry.go so that it registers
+// every model struct declared in the models directory.
+func UpdateModelRegistry() error {
+	models, err := findModels("models")
+	if err != nil {
+		return err
+	}
+
+	data := struct {
+		Models []string
+	}{
+		Models: models,
+	}
+
+	template, err := loadTemplate("registry")
+	if err != nil {
+		return err
+	}
+	content, err := renderFile(modelRegistryFile, template, data)
+	if err != nil {
+		return err
+	}
+	return writeFile(modelRegistryFile, content)
+}
+
+// findModels returns the names of the structs in dir that embed BaseModel
+// or gorm.Model.
+func findModels(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find model files: %w", err)
+	}
+
+	var models []string
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") || filepath.Base(file) == filepath.Base(modelRegistryFile) {
+			continue
+		}
+
+		parsed, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+
+		for _, decl := range parsed.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if structType, ok := typeSpec.Type.(*ast.StructType); ok && embedsBaseModel(structType) {
+					models = append(models, typeSpec.Name.Name)
+				}
+			}
+		}
+	}
+
+	sort.Strings(models)
+	return models, nil
+}
+
+func embedsBaseModel(structType *ast.StructType) bool {
+	for _, field := range structType.Fields.List {
+		if len(field.Names) > 0 {
+			continue
+		}
+		switch t := field.Type.(type) {
+		case *ast.Ident:
+			if t.Name == "BaseModel" {
+				return true
+			}
+		case *ast.SelectorExpr:
+			if pkg, ok := t.X.(*ast.Ident); ok && pkg.Name == "gorm" && t.Sel.Name == "Model" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ErrModelNotFound is returned by generators that need a model the models
+// package does not declare.
+var ErrModelNotFound = errors.New("model not found")
+
+// checkModel verifies that the models package declares modelName with the
+// repository, query options and request DTOs a generated controller uses.
+func checkModel(modelName string) error {
+	decls, err := parseDeclarations("models")
+	if err != nil {
+		return err
+	}
+
+	if !decls[modelName] {
+		return fmt.Errorf("%w: models/%s.go does not declare %s", ErrModelNotFound, strings.ToLower(modelName), modelName)
+	}
+
+	var missing []string
+	for _, name := range []string{
+		modelName + "Repository",
+		"New" + modelName + "Repository",
+		modelName + "Query",
+		modelName + "Repository.Create",
+		modelName + "Repository.GetByID",
+		modelName + "Repository.List",
+		modelName + "Repository.Update",
+		modelName + "Repository.Delete",
+	} {
+		if !decls[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("models package is missing %s; add them or regenerate the %s model", strings.Join(missing, ", "), modelName)
+	}
+	return nil
+}
+
+// parseDeclarations returns the names of the top-level types, functions,
+// variables and methods, as "Type.Method", declared in dir.
+func parseDeclarations(dir string) (map[string]bool, error) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(info fs.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", dir, err)
+	}
+
+	decls := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				switch d := decl.(type) {
+				case *ast.FuncDecl:
+					if d.Recv == nil || len(d.Recv.List) == 0 {
+						decls[d.Name.Name] = true
+						continue
+					}
+					recv := d.Recv.List[0].Type
+					if star, ok := recv.(*ast.StarExpr); ok {
+						recv = star.X
+					}
+					if ident, ok := recv.(*ast.Ident); ok {
+						decls[ident.Name+"."+d.Name.Name] = true
+					}
+				case *ast.GenDecl:
+					for _, spec := range d.Specs {
+						switch s := spec.(type) {
+						case *ast.TypeSpec:
+							decls[s.Name.Name] = true
+						case *ast.ValueSpec:
+							for _, name := range s.Names {
+								decls[name.Name] = true
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return decls, nil
+}
@@ -0,0 +1,319 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+const routesFile = "routes/routes.go"
+
+// routesSource is a parsed routes/routes.go. Edits are made at the offsets of
+// its AST nodes, rather than by printing a modified AST, so that comments stay
+// where they were; the result is then formatted.
+type routesSource struct {
+	content []byte
+	fset    *token.FileSet
+	file    *ast.File
+	setup   *ast.FuncDecl
+	// controllers is the name the project's controllers package is imported
+	// as, or "" if it is not imported.
+	controllers string
+	importSpec  *ast.ImportSpec
+}
+
+func parseRoutes(content []byte, modulePath string) (*routesSource, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, routesFile, content, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", routesFile, err)
+	}
+
+	src := &routesSource{content: content, fset: fset, file: file}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "SetupRoutes" && fn.Body != nil {
+			src.setup = fn
+		}
+	}
+	if src.setup == nil {
+		return nil, fmt.Errorf("failed to find SetupRoutes in %s", routesFile)
+	}
+
+	for _, spec := range file.Imports {
+		if path, _ := strconv.Unquote(spec.Path.Value); path == modulePath+"/controllers" {
+			src.controllers = "controllers"
+			if spec.Name != nil {
+				src.controllers = spec.Name.Name
+			}
+			src.importSpec = spec
+		}
+	}
+	return src, nil
+}
+
+func (src *routesSource) offset(pos token.Pos) int {
+	return src.fset.Position(pos).Offset
+}
+
+// app returns the name of SetupRoutes' *framework.App parameter.
+func (src *routesSource) app() string {
+	if params := src.setup.Type.Params.List; len(params) > 0 && len(params[0].Names) > 0 {
+		return params[0].Names[0].Name
+	}
+	return "app"
+}
+
+// router returns the router resource routes are registered on: the api
+// subrouter if SetupRoutes declares one, or else the application's router.
+func (src *routesSource) router() string {
+	for _, stmt := range src.setup.Body.List {
+		if assign, ok := stmt.(*ast.AssignStmt); ok && assign.Tok == token.DEFINE {
+			for _, lhs := range assign.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && ident.Name == "api" {
+					return "api"
+				}
+			}
+		}
+	}
+	return src.app() + ".Router"
+}
+
+// constructs reports whether node calls the constructor of a model's
+// controller, such as controllers.NewPostController.
+func constructs(node ast.Node, pkg, modelName string) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && isSelector(call.Fun, pkg, "New"+modelName+"Controller") {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func isSelector(expr ast.Expr, pkg, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == pkg
+}
+
+// usesPackage reports whether node refers to a member of the package
+// imported as pkg.
+func usesPackage(node ast.Node, pkg string) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			ident, ok := sel.X.(*ast.Ident)
+			found = found || ok && ident.Name == pkg
+		}
+		return !found
+	})
+	return found
+}
+
+// usesVariables reports whether node refers to any of the variables in names.
+func usesVariables(node ast.Node, names map[string]bool) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok {
+			found = found || names[ident.Name]
+		}
+		return !found
+	})
+	return found
+}
+
+// resourcePath returns the URL path segment of a model's routes, such as
+// "posts" for Post.
+func resourcePath(modelName string) string {
+	return schema.NamingStrategy{}.TableName(modelName)
+}
+
+// addResourceRoutes constructs a model's controller at the end of SetupRoutes
+// and registers its CRUD routes, on the api subrouter if SetupRoutes declares
+// one, importing the project's controllers package if needed. Nothing is
+// changed if SetupRoutes already constructs the controller.
+func addResourceRoutes(modelName, modulePath string) error {
+	content, err := os.ReadFile(routesFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", routesFile, err)
+	}
+	src, err := parseRoutes(content, modulePath)
+	if err != nil {
+		return err
+	}
+
+	pkg := src.controllers
+	if pkg == "" {
+		pkg = "controllers"
+	} else if constructs(src.file, pkg, modelName) {
+		return nil
+	}
+
+	router := src.router()
+	variable := strings.ToLower(modelName[:1]) + modelName[1:] + "Controller"
+	path := "/" + resourcePath(modelName)
+
+	var routes strings.Builder
+	fmt.Fprintf(&routes, "\n\t// %s routes\n", modelName)
+	fmt.Fprintf(&routes, "\t%s := %s.New%sController(%s.DB)\n", variable, pkg, modelName, src.app())
+	fmt.Fprintf(&routes, "\t%s.HandleFunc(%q, %s.GetAll%ss).Methods(\"GET\")\n", router, path, variable, modelName)
+	fmt.Fprintf(&routes, "\t%s.HandleFunc(%q, %s.Create%s).Methods(\"POST\")\n", router, path, variable, modelName)
+	fmt.Fprintf(&routes, "\t%s.HandleFunc(%q, %s.Get%s).Methods(\"GET\")\n", router, path+"/{id}", variable, modelName)
+	fmt.Fprintf(&routes, "\t%s.HandleFunc(%q, %s.Update%s).Methods(\"PUT\")\n", router, path+"/{id}", variable, modelName)
+	fmt.Fprintf(&routes, "\t%s.HandleFunc(%q, %s.Delete%s).Methods(\"DELETE\")\n", router, path+"/{id}", variable, modelName)
+
+	edits := []edit{{at: src.offset(src.setup.Body.Rbrace), text: routes.String()}}
+	if src.controllers == "" {
+		edits = append(edits, src.addImport(modulePath+"/controllers"))
+	}
+	return writeRoutes(apply(content, edits))
+}
+
+// removeResourceRoutes removes the construction of a model's controller from
+// SetupRoutes, with every statement using it and the "// Model routes"
+// comment above them, and drops the controllers import once nothing uses it.
+// It reports whether SetupRoutes constructed the controller.
+func removeResourceRoutes(modelName, modulePath string) (bool, error) {
+	content, err := os.ReadFile(routesFile)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", routesFile, err)
+	}
+	src, err := parseRoutes(content, modulePath)
+	if err != nil {
+		return false, err
+	}
+	if src.controllers == "" {
+		return false, nil
+	}
+
+	// The variables the controller is assigned to.
+	variables := make(map[string]bool)
+	for _, stmt := range src.setup.Body.List {
+		if assign, ok := stmt.(*ast.AssignStmt); ok && constructs(assign, src.controllers, modelName) {
+			for _, lhs := range assign.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && ident.Name != "_" {
+					variables[ident.Name] = true
+				}
+			}
+		}
+	}
+
+	var edits []edit
+	first := 0
+	for _, stmt := range src.setup.Body.List {
+		if constructs(stmt, src.controllers, modelName) || usesVariables(stmt, variables) {
+			edits = append(edits, src.removeLines(stmt.Pos(), stmt.End()))
+			if first == 0 {
+				first = src.fset.Position(stmt.Pos()).Line
+			}
+		}
+	}
+	if len(edits) == 0 {
+		return false, nil
+	}
+
+	for _, group := range src.file.Comments {
+		if strings.TrimSpace(group.Text()) == modelName+" routes" && src.fset.Position(group.End()).Line == first-1 {
+			edits = append(edits, src.removeLines(group.Pos(), group.End()))
+		}
+	}
+
+	content = apply(content, edits)
+
+	// Drop the import if the removed routes were its last use.
+	src, err = parseRoutes(content, modulePath)
+	if err != nil {
+		return false, err
+	}
+	if !usesPackage(src.file, src.controllers) {
+		var spec ast.Node = src.importSpec
+		for _, decl := range src.file.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT && len(gen.Specs) == 1 && gen.Specs[0] == src.importSpec {
+				spec = gen
+			}
+		}
+		content = apply(content, []edit{src.removeLines(spec.Pos(), spec.End())})
+	}
+
+	return true, writeRoutes(content)
+}
+
+// addImport returns the edit importing path, in the first import declaration
+// if there is one.
+func (src *routesSource) addImport(path string) edit {
+	for _, decl := range src.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if gen.Lparen.IsValid() {
+			return edit{at: src.offset(gen.Rparen), text: fmt.Sprintf("\t%q\n", path)}
+		}
+		return edit{at: src.offset(gen.End()), text: fmt.Sprintf("\nimport %q", path)}
+	}
+	return edit{at: src.offset(src.file.Name.End()), text: fmt.Sprintf("\n\nimport %q\n", path)}
+}
+
+// removeLines returns the edit removing the lines from pos to end, along with
+// a blank line above them so that removing a block separated by blank lines
+// does not leave two behind.
+func (src *routesSource) removeLines(pos, end token.Pos) edit {
+	start := bytes.LastIndexByte(src.content[:src.offset(pos)], '\n') + 1
+	stop := len(src.content)
+	if i := bytes.IndexByte(src.content[src.offset(end):], '\n'); i >= 0 {
+		stop = src.offset(end) + i + 1
+	}
+	if start >= 2 && src.content[start-1] == '\n' && len(bytes.TrimSpace(src.content[bytes.LastIndexByte(src.content[:start-1], '\n')+1:start-1])) == 0 {
+		start = bytes.LastIndexByte(src.content[:start-1], '\n') + 1
+	}
+	return edit{at: start, remove: stop - start}
+}
+
+// edit inserts text at an offset, after removing the given number of bytes.
+type edit struct {
+	at     int
+	remove int
+	text   string
+}
+
+// apply makes edits to content. Overlapping removals are merged.
+func apply(content []byte, edits []edit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].at < edits[j].at })
+
+	var out bytes.Buffer
+	last := 0
+	for _, e := range edits {
+		if e.at < last {
+			e.remove -= last - e.at
+			e.at = last
+		}
+		out.Write(content[last:e.at])
+		out.WriteString(e.text)
+		last = e.at + max(e.remove, 0)
+	}
+	out.Write(content[last:])
+	return out.Bytes()
+}
+
+func writeRoutes(content []byte) error {
+	formatted, err := formatSource(routesFile, content)
+	if err != nil {
+		return err
+	}
+	return writeFile(routesFile, formatted)
+}
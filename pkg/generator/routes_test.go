@@ -0,0 +1,263 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testModule = "example.com/app"
+
+// inProject changes into a temporary project directory holding files, and
+// back when the test ends.
+func inProject(t *testing.T, files map[string]string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	files["go.mod"] = "module " + testModule + "\n\ngo 1.21\n"
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	SetWriteOptions(WriteOptions{Output: &strings.Builder{}})
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+const apiRoutes = `package routes
+
+import (
+	"github.com/ThreadBolt/threadbolt/pkg/framework"
+
+	"example.com/app/controllers"
+)
+
+// SetupRoutes registers the application's routes.
+func SetupRoutes(app *framework.App) {
+	// Health check
+	app.Router.HandleFunc("/health", controllers.HealthCheck).Methods("GET")
+
+	// API routes
+	api := app.Router.PathPrefix("/api/v1").Subrouter()
+	api.HandleFunc("/status", controllers.StatusCheck).Methods("GET")
+}
+`
+
+const bareRoutes = `package routes
+
+import "github.com/ThreadBolt/threadbolt/pkg/framework"
+
+// SetupRoutes registers the application's routes.
+func SetupRoutes(a *framework.App) {
+	a.Router.Use(framework.Logger)
+}
+`
+
+const aliasedRoutes = `package routes
+
+import (
+	"github.com/ThreadBolt/threadbolt/pkg/framework"
+
+	ctrl "example.com/app/controllers"
+)
+
+// SetupRoutes registers the application's routes.
+func SetupRoutes(app *framework.App) {
+	// Comment routes
+	comments := ctrl.NewCommentController(app.DB)
+	app.Router.HandleFunc("/comments", comments.GetAllComments).Methods("GET")
+}
+`
+
+func TestResourceRoutes(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes string
+		added  []string
+		// removed is the file left once the routes are removed, if it is
+		// not routes.
+		removed string
+	}{
+		{
+			name:   "api subrouter",
+			routes: apiRoutes,
+			added: []string{
+				"\t// BlogPost routes\n\tblogPostController := controllers.NewBlogPostController(app.DB)\n",
+				`api.HandleFunc("/blog_posts", blogPostController.GetAllBlogPosts).Methods("GET")`,
+				`api.HandleFunc("/blog_posts/{id}", blogPostController.DeleteBlogPost).Methods("DELETE")`,
+			},
+		},
+		{
+			name:   "no controllers import",
+			routes: bareRoutes,
+			added: []string{
+				"\t\"example.com/app/controllers\"\n",
+				`blogPostController := controllers.NewBlogPostController(a.DB)`,
+				`a.Router.HandleFunc("/blog_posts", blogPostController.CreateBlogPost).Methods("POST")`,
+			},
+			removed: strings.Replace(bareRoutes, `import "github.com/ThreadBolt/threadbolt/pkg/framework"`,
+				"import (\n\t\"github.com/ThreadBolt/threadbolt/pkg/framework\"\n)", 1),
+		},
+		{
+			name:   "aliased import",
+			routes: aliasedRoutes,
+			added: []string{
+				`blogPostController := ctrl.NewBlogPostController(app.DB)`,
+				`app.Router.HandleFunc("/blog_posts/{id}", blogPostController.UpdateBlogPost).Methods("PUT")`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inProject(t, map[string]string{routesFile: tt.routes})
+
+			if err := addResourceRoutes("BlogPost", testModule); err != nil {
+				t.Fatal(err)
+			}
+			added := readFile(t, routesFile)
+			for _, want := range tt.added {
+				if !strings.Contains(added, want) {
+					t.Errorf("routes lack %q:\n%s", want, added)
+				}
+			}
+
+			if err := addResourceRoutes("BlogPost", testModule); err != nil {
+				t.Fatal(err)
+			}
+			if again := readFile(t, routesFile); again != added {
+				t.Errorf("routes added twice:\n%s", again)
+			}
+
+			removed, err := removeResourceRoutes("BlogPost", testModule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !removed {
+				t.Error("removeResourceRoutes reported no routes")
+			}
+			want := tt.removed
+			if want == "" {
+				want = tt.routes
+			}
+			if got := readFile(t, routesFile); got != want {
+				t.Errorf("routes after removal:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestRemoveResourceRoutesKeepsOthers(t *testing.T) {
+	inProject(t, map[string]string{routesFile: aliasedRoutes})
+
+	removed, err := removeResourceRoutes("Post", testModule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed {
+		t.Error("removeResourceRoutes reported routes for Post")
+	}
+
+	// Removing Comment leaves the Post routes added after it.
+	if err := addResourceRoutes("Post", testModule); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := removeResourceRoutes("Comment", testModule); err != nil {
+		t.Fatal(err)
+	}
+	got := readFile(t, routesFile)
+	if strings.Contains(got, "omment") {
+		t.Errorf("Comment routes left behind:\n%s", got)
+	}
+	if !strings.Contains(got, "// Post routes\n\tpostController := ctrl.NewPostController(app.DB)") {
+		t.Errorf("Post routes removed:\n%s", got)
+	}
+}
+
+func TestDestroyController(t *testing.T) {
+	inProject(t, map[string]string{
+		routesFile:                       apiRoutes,
+		"controllers/post_controller.go": "package controllers\n",
+		"tests/post_controller_test.go":  "package tests\n",
+		"models/post.go":                 "package models\n",
+	})
+	if err := addResourceRoutes("Post", testModule); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := DestroyController("Post")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{routesFile, "controllers/post_controller.go", "tests/post_controller_test.go"}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+	if got := readFile(t, routesFile); got != apiRoutes {
+		t.Errorf("routes after destroy:\n%s", got)
+	}
+	for _, path := range want[1:] {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s not removed", path)
+		}
+	}
+	if _, err := os.Stat("models/post.go"); err != nil {
+		t.Errorf("model removed: %v", err)
+	}
+
+	if _, err := DestroyController("Post"); err == nil {
+		t.Error("destroying a missing controller succeeded")
+	}
+}
+
+func TestDestroyControllerDryRun(t *testing.T) {
+	inProject(t, map[string]string{
+		routesFile:                       apiRoutes,
+		"controllers/post_controller.go": "package controllers\n",
+	})
+	if err := addResourceRoutes("Post", testModule); err != nil {
+		t.Fatal(err)
+	}
+	routes := readFile(t, routesFile)
+
+	var out strings.Builder
+	SetWriteOptions(WriteOptions{DryRun: true, Output: &out})
+	if _, err := DestroyController("Post"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readFile(t, routesFile); got != routes {
+		t.Errorf("dry run changed routes:\n%s", got)
+	}
+	if _, err := os.Stat("controllers/post_controller.go"); err != nil {
+		t.Errorf("dry run removed the controller: %v", err)
+	}
+	for _, want := range []string{"update  " + routesFile, "remove  controllers/post_controller.go"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("dry run output lacks %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenerateScaffold writes everything a resource needs: the model with its
+// repository and request DTOs, a controller, routes registered in
+// SetupRoutes and table-driven tests. If any step fails, the files written so
+// far are restored.
+func GenerateScaffold(modelName string, fields []Field) error {
+	module, err := modulePath()
+	if err != nil {
+		return err
+	}
+
+	return Transaction(func() error {
+		if err := GenerateModel(modelName, fields); err != nil {
+			return err
+		}
+		if err := generateController(modelName, module); err != nil {
+			return err
+		}
+		if err := addResourceRoutes(modelName, module); err != nil {
+			return err
+		}
+		return generateControllerTest(modelName, fields, module)
+	})
+}
+
+// generateControllerTest writes table-driven tests exercising a generated
+// controller's endpoints against an in-memory SQLite database.
+func generateControllerTest(modelName string, fields []Field, modulePath string) error {
+	fileName := controllerTestFileName(modelName)
+	if len(fields) == 0 {
+		fields = defaultFields
+	}
+
+	template, err := loadTemplate("controller_test")
+	if err != nil {
+		return err
+	}
+
+	var body []string
+	hasRequired := false
+	for _, field := range fields {
+		body = append(body, fmt.Sprintf("%q: %s", field.Column, field.Sample()))
+		hasRequired = hasRequired || strings.Contains(field.CreateRules(), "required")
+	}
+
+	data := struct {
+		ModelName   string
+		Module      string
+		Path        string
+		Body        string
+		HasRequired bool
+	}{
+		ModelName:   modelName,
+		Module:      modulePath,
+		Path:        "/" + resourcePath(modelName),
+		Body:        "{" + strings.Join(body, ", ") + "}",
+		HasRequired: hasRequired,
+	}
+
+	return generateFile(fileName, template, data)
+}
@@ -1,298 +1,110 @@
 package generator
 
-const mainGoTemplate = `package main
-
 import (
-	"log"
-
-	"github.com/ThreadBolt/threadbolt/pkg/framework"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/jinzhu/inflection"
 )
 
-func main() {
-	app, err := framework.LoadApp()
-	if err != nil {
-		log.Fatalf("Failed to load application: %v", err)
-	}
+// The generators' templates, such as templates/model.tmpl. Those under
+// templates/project make up the built-in project templates.
+//
+//go:embed templates
+var templateFS embed.FS
+
+// TemplateDir is where a project keeps its own versions of the generators'
+// templates, such as .threadbolt/templates/model.tmpl, which are used instead
+// of the built-in ones.
+const TemplateDir = ".threadbolt/templates"
+
+// templateFuncs are available to every template.
+var templateFuncs = template.FuncMap{
+	"pluralize":  inflection.Plural,
+	"snake_case": toSnakeCase,
+	"camelCase":  toCamelCase,
+}
 
-	port := app.Config.GetString("server.port")
-	if port == "" {
-		port = "8080"
+// loadTemplate returns the template name, such as "model" or
+// "auth/controller", from the project's TemplateDir if it has one, or else
+// the built-in template.
+func loadTemplate(name string) (string, error) {
+	override := filepath.Join(TemplateDir, filepath.FromSlash(name)+".tmpl")
+	content, err := os.ReadFile(override)
+	if err == nil {
+		return string(content), nil
 	}
-
-	log.Printf("Starting {{.AppName}} on port %s", port)
-	if err := app.Start(port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read %s: %w", override, err)
 	}
-}
-`
-
-const goModTemplate = `module {{.AppName}}
-
-go 1.21
-
-require (
-	github.com/ThreadBolt/threadbolt v0.1.0
-)
-`
-
-const configTemplate = `server:
-  port: 8080
-  host: localhost
-
-database:
-  driver: sqlite
-  name: {{.AppName}}.db
-
-logging:
-  level: info
-  format: text
-
-environment: development
-`
-
-const routesTemplate = `package routes
-
-import (
-	"{{.AppName}}/controllers"
-	"github.com/gorilla/mux"
-	"github.com/ThreadBolt/threadbolt/pkg/framework"
-)
-
-func SetupRoutes(app *framework.App) {
-	// Health check
-	app.Router.HandleFunc("/health", controllers.HealthCheck).Methods("GET")
-
-	// API routes
-	api := app.Router.PathPrefix("/api/v1").Subrouter()
-	api.HandleFunc("/status", controllers.StatusCheck).Methods("GET")
-}
-`
 
-const healthControllerTemplate = `package controllers
-
-import (
-	"encoding/json"
-	"net/http"
-	"time"
-)
-
-type HealthResponse struct {
-	Status    string    ` + "`json:\"status\"`" + `
-	Timestamp time.Time ` + "`json:\"timestamp\"`" + `
-	Service   string    ` + "`json:\"service\"`" + `
-}
-
-type StatusResponse struct {
-	Message string ` + "`json:\"message\"`" + `
-	Version string ` + "`json:\"version\"`" + `
-}
-
-func HealthCheck(w http.ResponseWriter, r *http.Request) {
-	response := HealthResponse{
-		Status:    "healthy",
-		Timestamp: time.Now(),
-		Service:   "{{.AppName}}",
+	content, err = templateFS.ReadFile("templates/" + name + ".tmpl")
+	if err != nil {
+		return "", fmt.Errorf("unknown template %s", name)
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	return string(content), nil
 }
 
-func StatusCheck(w http.ResponseWriter, r *http.Request) {
-	response := StatusResponse{
-		Message: "{{.AppName}} API is running",
-		Version: "1.0.0",
+// builtin returns an embedded file of the built-in project templates, such as
+// "project/main.tmpl".
+func builtin(name string) string {
+	content, err := templateFS.ReadFile("templates/" + name)
+	if err != nil {
+		panic(err)
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	return string(content)
 }
-`
-
-const baseModelTemplate = `package models
-
-import (
-	"time"
-
-	"gorm.io/gorm"
-)
 
-// BaseModel contains common fields for all models
-type BaseModel struct {
-	ID        uint           ` + "`gorm:\"primarykey\" json:\"id\"`" + `
-	CreatedAt time.Time      ` + "`json:\"created_at\"`" + `
-	UpdatedAt time.Time      ` + "`json:\"updated_at\"`" + `
-	DeletedAt gorm.DeletedAt ` + "`gorm:\"index\" json:\"-\"`" + `
+// TemplateNames returns the names of the templates a project can override,
+// such as "model" and "auth/controller".
+func TemplateNames() []string {
+	var names []string
+	fs.WalkDir(templateFS, "templates", func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && file == "templates/project" {
+			return fs.SkipDir
+		}
+		if name, ok := strings.CutSuffix(strings.TrimPrefix(file, "templates/"), ".tmpl"); ok && !entry.IsDir() {
+			names = append(names, name)
+		}
+		return nil
+	})
+	sort.Strings(names)
+	return names
 }
-`
-
-const corsMiddlewareTemplate = `package middleware
 
-import (
-	"net/http"
-)
-
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+// EjectTemplates copies the named built-in templates, or all of them if none
+// are named, to the project's TemplateDir to be edited, and returns their
+// paths.
+func EjectTemplates(names []string) ([]string, error) {
+	if len(names) == 0 {
+		names = TemplateNames()
+	}
 
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+	var paths []string
+	err := Transaction(func() error {
+		for _, name := range names {
+			name = strings.TrimSuffix(path.Clean(filepath.ToSlash(name)), ".tmpl")
+			if !contains(TemplateNames(), name) {
+				return fmt.Errorf("unknown template %s: use one of %s", name, strings.Join(TemplateNames(), ", "))
+			}
+
+			file := filepath.Join(TemplateDir, filepath.FromSlash(name)+".tmpl")
+			if err := createFile(file, []byte(builtin(name+".tmpl"))); err != nil {
+				return err
+			}
+			paths = append(paths, file)
 		}
-
-		next.ServeHTTP(w, r)
+		return nil
 	})
+	return paths, err
 }
-`
-
-const gitignoreTemplate = `# Binaries
-*.exe
-*.exe~
-*.dll
-*.so
-*.dylib
-
-# Test binary, built with go test -c
-*.test
-
-# Output of the go coverage tool
-*.out
-
-# Go workspace file
-go.work
-
-# Database files
-*.db
-*.sqlite
-
-# Environment files
-.env
-.env.local
-
-# IDE files
-.vscode/
-.idea/
-*.swp
-*.swo
-
-# Logs
-*.log
-
-# OS generated files
-.DS_Store
-.DS_Store?
-._*
-.Spotlight-V100
-.Trashes
-ehthumbs.db
-Thumbs.db
-`
-
-const readmeTemplate = `# {{.AppName}}
-
-A ThreadBolt application generated with the ThreadBolt framework.
-
-## Getting Started
-
-### Prerequisites
-
-- Go 1.21 or later
-- ThreadBolt CLI tool
-
-### Running the Application
-
-1. Install dependencies:
-   ` + "```bash" + `
-   go mod tidy
-   ` + "```" + `
-
-2. Run the application:
-   ` + "```bash" + `
-   threadbolt run
-   ` + "```" + `
-
-   Or use Go directly:
-   ` + "```bash" + `
-   go run main.go
-   ` + "```" + `
-
-3. The application will start on http://localhost:8080
-
-### Available Endpoints
-
-- GET /health - Health check endpoint
-- GET /api/v1/status - Status endpoint
-
-### Project Structure
-
-` + "```" + `
-{{.AppName}}/
-├── cmd/               # CLI entry points
-├── config/            # Configuration files
-├── controllers/       # MVC controllers
-├── internal/          # Internal packages
-│   ├── middleware/    # Custom middleware
-│   └── services/      # Business logic services
-├── models/            # ORM models
-├── migrations/        # Database migration files
-├── public/            # Static assets
-├── routes/            # Route definitions
-├── templates/         # View templates
-├── tests/             # Unit and integration tests
-├── go.mod             # Go modules
-└── main.go            # Application entry point
-` + "```" + `
-
-### CLI Commands
-
-- ` + "`threadbolt new <app-name>`" + ` - Create a new ThreadBolt application
-- ` + "`threadbolt generate model <name>`" + ` - Generate a new model
-- ` + "`threadbolt generate controller <name>`" + ` - Generate a new controller
-- ` + "`threadbolt migrate`" + ` - Run database migrations
-- ` + "`threadbolt run`" + ` - Start the development server
-- ` + "`threadbolt test`" + ` - Run tests
-
-### Configuration
-
-Configuration is handled through ` + "`config/config.yaml`" + ` and environment variables.
-Environment variables should be prefixed with ` + "`THREADBOLT_`" + `.
-
-Example:
-- ` + "`THREADBOLT_SERVER_PORT=3000`" + `
-- ` + "`THREADBOLT_DATABASE_DRIVER=postgres`" + `
-
-## Development
-
-### Adding a New Model
-
-` + "```bash" + `
-threadbolt generate model User
-` + "```" + `
-
-### Adding a New Controller
-
-` + "```bash" + `
-threadbolt generate controller User
-` + "```" + `
-
-### Running Tests
-
-` + "```bash" + `
-threadbolt test
-` + "```" + `
-
-### Database Migrations
-
-` + "```bash" + `
-threadbolt migrate
-` + "```" + `
-
-## License
-
-This project is licensed under the MIT License.
-`
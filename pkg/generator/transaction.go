@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// fileTransaction records the original content of the files written while it
+// is active, so that a failed generator can put them back.
+type fileTransaction struct {
+	originals map[string][]byte
+	created   map[string]bool
+	order     []string
+}
+
+var activeTransaction *fileTransaction
+
+// Transaction runs fn and, if it fails, restores the files generators wrote
+// meanwhile to their previous content and removes the files they created.
+// Transactions do not nest: fn's generators join the outer transaction.
+func Transaction(fn func() error) error {
+	if activeTransaction != nil {
+		return fn()
+	}
+
+	tx := &fileTransaction{originals: make(map[string][]byte), created: make(map[string]bool)}
+	activeTransaction = tx
+	defer func() { activeTransaction = nil }()
+
+	if err := fn(); err != nil {
+		if rollbackErr := tx.rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w (rolling back generated files failed: %v)", err, rollbackErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// record saves the content path has before the transaction first changes it.
+func (tx *fileTransaction) record(path string) error {
+	if tx == nil {
+		return nil
+	}
+	if _, recorded := tx.originals[path]; recorded {
+		return nil
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	tx.originals[path] = original
+	tx.created[path] = os.IsNotExist(err)
+	tx.order = append(tx.order, path)
+	return nil
+}
+
+func (tx *fileTransaction) rollback() error {
+	var errs []error
+	for i := len(tx.order) - 1; i >= 0; i-- {
+		path := tx.order[i]
+
+		var err error
+		if tx.created[path] {
+			err = os.Remove(path)
+		} else {
+			err = os.WriteFile(path, tx.originals[path], 0644)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,195 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/tools/imports"
+)
+
+// ErrConflict is returned when a generator would overwrite a file whose
+// content differs from what it generates, and neither WriteOptions.Force nor
+// WriteOptions.Skip is set.
+var ErrConflict = errors.New("file already exists")
+
+// WriteOptions control how generators write files. Files that generators
+// merge into, such as routes/routes.go and models/registry.go, are always
+// updated; the options only decide about files that would be replaced.
+type WriteOptions struct {
+	// Force overwrites conflicting files and Skip keeps them. Without
+	// either, Confirm is asked, and generators fail with ErrConflict if it
+	// is nil.
+	Force bool
+	Skip  bool
+	// Confirm asks whether to overwrite path; diff shows the change.
+	Confirm func(path, diff string) bool
+	// DryRun reports the files generators would change instead of writing
+	// them.
+	DryRun bool
+	// Diff prints a unified diff of every change to an existing file.
+	Diff bool
+	// Output receives reports and diffs. It defaults to os.Stdout.
+	Output io.Writer
+}
+
+var (
+	writeOptions WriteOptions
+	reported     = make(map[string]bool)
+	// skipped and changed count the files kept and written since the write
+	// options were set.
+	skipped, changed int
+)
+
+// SetWriteOptions sets the options of the generators run afterwards.
+func SetWriteOptions(opts WriteOptions) {
+	writeOptions = opts
+	reported = make(map[string]bool)
+	skipped, changed = 0, 0
+}
+
+// SkippedAll reports whether the generators run since SetWriteOptions kept
+// existing files and changed none.
+func SkippedAll() bool {
+	return skipped > 0 && changed == 0
+}
+
+// report prints a line such as "create  models/post.go". Every file is
+// reported once, as dry runs generate some files twice when a later step
+// cannot see an earlier one's output.
+func report(action, path string) {
+	if reported[path] {
+		return
+	}
+	reported[path] = true
+	fmt.Fprintf(output(), "%10s  %s\n", action, path)
+}
+
+func printDiff(path string, old, new []byte) {
+	fmt.Fprint(output(), unifiedDiff(path, old, new))
+}
+
+func output() io.Writer {
+	if writeOptions.Output == nil {
+		return os.Stdout
+	}
+	return writeOptions.Output
+}
+
+// createFile writes a generated file, deciding what to do about an existing
+// file with different content according to the write options.
+func createFile(path string, content []byte) error {
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) || (err == nil && bytes.Equal(existing, content)) {
+		return writeFile(path, content)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	opts := writeOptions
+	switch {
+	case opts.Force:
+	case opts.Skip:
+		report("skip", path)
+		skipped++
+		return nil
+	case opts.DryRun:
+		report("conflict", path)
+		if opts.Diff {
+			printDiff(path, existing, content)
+		}
+		return nil
+	case opts.Confirm != nil:
+		if !opts.Confirm(path, unifiedDiff(path, existing, content)) {
+			report("skip", path)
+			skipped++
+			return nil
+		}
+	default:
+		return fmt.Errorf("%w: %s (pass --force to overwrite it or --skip to keep it)", ErrConflict, path)
+	}
+	return writeFile(path, content)
+}
+
+// writeFile writes content to path, creating its directory, unless the file
+// already has that content. The previous content is recorded in the active
+// transaction; with DryRun set, the change is only reported.
+func writeFile(path string, content []byte) error {
+	return writeFileMode(path, content, 0644)
+}
+
+func writeFileMode(path string, content []byte, perm os.FileMode) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	exists := err == nil
+	if exists && bytes.Equal(existing, content) {
+		return nil
+	}
+	changed++
+
+	if writeOptions.DryRun {
+		if exists {
+			report("update", path)
+		} else {
+			report("create", path)
+		}
+	}
+	if writeOptions.Diff && exists {
+		printDiff(path, existing, content)
+	}
+	if writeOptions.DryRun {
+		return nil
+	}
+
+	if err := activeTransaction.record(path); err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+	if err := os.WriteFile(path, content, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// removeFile removes path, recording its content in the active transaction.
+func removeFile(path string) error {
+	changed++
+	if writeOptions.DryRun {
+		report("remove", path)
+		return nil
+	}
+
+	if err := activeTransaction.record(path); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return nil
+}
+
+// formatSource formats Go source as goimports does: gofmt'ed, with unused
+// imports removed and imports grouped into the standard library, third-party
+// packages and, inside a project, the project's own packages.
+func formatSource(path string, src []byte) ([]byte, error) {
+	imports.LocalPrefix = ""
+	if module, err := modulePath(); err == nil {
+		imports.LocalPrefix = module + "/"
+	}
+
+	formatted, err := imports.Process(path, src, &imports.Options{Comments: true, TabIndent: true, TabWidth: 8})
+	if err != nil {
+		return nil, fmt.Errorf("failed to format %s: %w", path, err)
+	}
+	return formatted, nil
+}
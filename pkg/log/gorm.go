@@ -0,0 +1,88 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// DefaultSlowThreshold is the query duration above which SQL is logged as a
+// warning.
+const DefaultSlowThreshold = 200 * time.Millisecond
+
+// GormLogger adapts a slog.Logger to GORM's logger.Interface. Every query is
+// logged at debug level, slow queries as warnings and failed queries as
+// errors, so SQL logs follow the configured level and format. Sessions set to
+// GORM's Info level, such as db.Debug(), log every query at info level
+// instead, so they print whatever the configured level.
+type GormLogger struct {
+	logger        *slog.Logger
+	level         gormlogger.LogLevel
+	SlowThreshold time.Duration
+}
+
+// NewGormLogger creates a GORM logger that writes to logger.
+func NewGormLogger(logger *slog.Logger) *GormLogger {
+	return &GormLogger{
+		logger:        logger,
+		level:         gormlogger.Warn,
+		SlowThreshold: DefaultSlowThreshold,
+	}
+}
+
+// LogMode returns a copy of the logger limited to level, as used by
+// db.Debug() and silent sessions.
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	switch {
+	case l.level >= gormlogger.Info:
+		l.logger.InfoContext(ctx, fmt.Sprintf(msg, data...))
+	case l.level >= gormlogger.Warn:
+		l.logger.DebugContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.logger.WarnContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.logger.ErrorContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+
+	switch {
+	case err != nil && l.level >= gormlogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		l.logger.ErrorContext(ctx, "sql failed", "error", err, "sql", sql, "rows", rows, "elapsed", elapsed)
+	case l.SlowThreshold > 0 && elapsed > l.SlowThreshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		l.logger.WarnContext(ctx, "slow sql", "sql", sql, "rows", rows, "elapsed", elapsed, "threshold", l.SlowThreshold)
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		l.logger.InfoContext(ctx, "sql", "sql", sql, "rows", rows, "elapsed", elapsed)
+	case l.level >= gormlogger.Warn && l.logger.Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		l.logger.DebugContext(ctx, "sql", "sql", sql, "rows", rows, "elapsed", elapsed)
+	}
+}
@@ -0,0 +1,74 @@
+// Package log builds the structured loggers used across ThreadBolt from the
+// logging.level and logging.format configuration keys.
+package log
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Config selects the level, format and destination of a logger.
+type Config struct {
+	// Level is one of debug, info, warn or error. It defaults to info.
+	Level string
+	// Format is text or json. It defaults to text.
+	Format string
+	// Output defaults to os.Stderr.
+	Output io.Writer
+}
+
+// New creates a logger from cfg.
+func New(cfg Config) (*slog.Logger, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	output := cfg.Output
+	if output == nil {
+		output = os.Stderr
+	}
+
+	handlerOptions := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "", "text":
+		handler = slog.NewTextHandler(output, handlerOptions)
+	case "json":
+		handler = slog.NewJSONHandler(output, handlerOptions)
+	default:
+		return nil, fmt.Errorf("unsupported log format: %s", cfg.Format)
+	}
+
+	return slog.New(handler), nil
+}
+
+// FromConfig creates a logger from the logging.level and logging.format keys.
+func FromConfig(v *viper.Viper) (*slog.Logger, error) {
+	return New(Config{
+		Level:  v.GetString("logging.level"),
+		Format: v.GetString("logging.format"),
+	})
+}
+
+// ParseLevel converts a configured level name to a slog.Level.
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unsupported log level: %s", level)
+	}
+}
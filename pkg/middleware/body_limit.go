@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/ThreadBolt/threadbolt/pkg/problem"
+)
+
+// BodyLimit rejects requests whose declared Content-Length exceeds maxBytes
+// with 413 and caps the readable body of all other requests, so handlers
+// reading past the limit get an *http.MaxBytesError.
+func BodyLimit(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if maxBytes <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.ContentLength > maxBytes {
+				problem.Write(w, problem.New(http.StatusRequestEntityTooLarge, "request body too large"))
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// CORSConfig configures CORS.
+type CORSConfig struct {
+	Enabled bool
+	// AllowedOrigins lists the origins allowed to make cross-origin
+	// requests. "*" allows any origin, entries containing "*" such as
+	// "https://*.example.com" match any sequence of characters in its place,
+	// and entries starting with "^" are regular expressions.
+	AllowedOrigins []string
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers a preflight may ask for; "*"
+	// allows any header.
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response; zero
+	// omits Access-Control-Max-Age.
+	MaxAge time.Duration
+}
+
+// CORS applies a cross-origin resource sharing policy. Preflight requests are
+// answered directly, so CORS must wrap the router rather than be installed
+// with Router.Use: gorilla/mux rejects an OPTIONS request before running
+// route middleware unless the route lists OPTIONS itself. When next is a
+// *mux.Router, preflights for paths with no route fall through to it and
+// only the methods routed for the path are advertised.
+func CORS(cfg CORSConfig) func(http.Handler) http.Handler {
+	policy := newCORSPolicy(cfg)
+
+	return func(next http.Handler) http.Handler {
+		router, _ := next.(*mux.Router)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Responses differ by origin even when none is sent, so caches
+			// must not reuse a response across origins.
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				w.Header().Add("Vary", "Origin")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				methods := policy.methods
+				if router != nil {
+					methods = routedMethods(router, r, policy.methods)
+					if methods == nil {
+						next.ServeHTTP(w, r)
+						return
+					}
+				}
+				policy.preflight(w, r, origin, methods)
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+			if policy.allowOrigin(origin) {
+				policy.setOrigin(w, origin)
+				if len(policy.exposedHeaders) > 0 {
+					w.Header().Set("Access-Control-Expose-Headers", strings.Join(policy.exposedHeaders, ", "))
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type corsPolicy struct {
+	anyOrigin        bool
+	origins          map[string]bool
+	patterns         []*regexp.Regexp
+	methods          []string
+	anyHeader        bool
+	headers          map[string]bool
+	exposedHeaders   []string
+	allowCredentials bool
+	maxAge           time.Duration
+}
+
+func newCORSPolicy(cfg CORSConfig) *corsPolicy {
+	p := &corsPolicy{
+		origins:          make(map[string]bool),
+		headers:          make(map[string]bool),
+		exposedHeaders:   cfg.ExposedHeaders,
+		allowCredentials: cfg.AllowCredentials,
+		maxAge:           cfg.MaxAge,
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.TrimSpace(origin)
+		switch {
+		case origin == "*":
+			p.anyOrigin = true
+		case strings.HasPrefix(origin, "^"):
+			pattern, err := regexp.Compile(origin)
+			if err != nil {
+				slog.Warn("ignoring invalid CORS origin pattern", "pattern", origin, "error", err)
+				continue
+			}
+			p.patterns = append(p.patterns, pattern)
+		case strings.Contains(origin, "*"):
+			quoted := strings.ReplaceAll(regexp.QuoteMeta(strings.ToLower(origin)), `\*`, ".*")
+			p.patterns = append(p.patterns, regexp.MustCompile("^"+quoted+"$"))
+		default:
+			p.origins[strings.ToLower(origin)] = true
+		}
+	}
+
+	for _, method := range cfg.AllowedMethods {
+		p.methods = append(p.methods, strings.ToUpper(strings.TrimSpace(method)))
+	}
+
+	for _, header := range cfg.AllowedHeaders {
+		header = strings.TrimSpace(header)
+		if header == "*" {
+			p.anyHeader = true
+			continue
+		}
+		p.headers[http.CanonicalHeaderKey(header)] = true
+	}
+
+	return p
+}
+
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if p.origins[origin] {
+		return true
+	}
+	for _, pattern := range p.patterns {
+		if pattern.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// setOrigin answers with "*" only when any origin is allowed and no
+// credentials are involved; browsers reject the wildcard otherwise.
+func (p *corsPolicy) setOrigin(w http.ResponseWriter, origin string) {
+	if p.anyOrigin && !p.allowCredentials {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+	if p.allowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// preflight answers an OPTIONS preflight. A disallowed origin, method or
+// header gets a response without CORS headers, which the browser treats as
+// a refusal.
+func (p *corsPolicy) preflight(w http.ResponseWriter, r *http.Request, origin string, methods []string) {
+	w.Header().Add("Vary", "Origin")
+	w.Header().Add("Vary", "Access-Control-Request-Method")
+	w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+	if !p.allowOrigin(origin) || !contains(methods, strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	requested := requestedHeaders(r)
+	if !p.anyHeader {
+		for _, header := range requested {
+			if !p.headers[http.CanonicalHeaderKey(header)] {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+	}
+
+	p.setOrigin(w, origin)
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	if len(requested) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+	}
+	if p.maxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.FormatInt(int64(p.maxAge.Seconds()), 10))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// routedMethods returns the allowed methods that have a route for the
+// preflighted path, or nil when no route matches the path at all.
+func routedMethods(router *mux.Router, r *http.Request, allowed []string) []string {
+	var methods []string
+	pathMatched := false
+
+	for _, method := range allowed {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+
+		var match mux.RouteMatch
+		matched := router.Match(probe, &match)
+		switch {
+		case matched && match.MatchErr == nil:
+			methods = append(methods, method)
+			pathMatched = true
+		case match.MatchErr == mux.ErrMethodMismatch:
+			pathMatched = true
+		}
+	}
+
+	if !pathMatched {
+		return nil
+	}
+	return methods
+}
+
+func requestedHeaders(r *http.Request) []string {
+	var headers []string
+	for _, value := range r.Header.Values("Access-Control-Request-Headers") {
+		for _, header := range strings.Split(value, ",") {
+			if header = strings.TrimSpace(header); header != "" {
+				headers = append(headers, header)
+			}
+		}
+	}
+	return headers
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
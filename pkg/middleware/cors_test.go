@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func newCORSHandler(cfg CORSConfig) http.Handler {
+	router := mux.NewRouter()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	router.HandleFunc("/posts", ok).Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc("/posts/{id}", ok).Methods(http.MethodGet, http.MethodDelete)
+	return CORS(cfg)(router)
+}
+
+func TestCORSPreflight(t *testing.T) {
+	cfg := CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com", "https://*.example.org", `^http://localhost:\d+$`},
+		AllowedMethods: []string{"GET", "POST", "PUT"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+		MaxAge:         10 * time.Minute,
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		origin      string
+		method      string
+		headers     string
+		status      int
+		allowOrigin string
+		methods     string
+	}{
+		{
+			name: "allowed", path: "/posts", origin: "https://app.example.com", method: "POST", headers: "content-type",
+			status: http.StatusNoContent, allowOrigin: "https://app.example.com", methods: "GET, POST",
+		},
+		{
+			name: "wildcard origin", path: "/posts", origin: "https://admin.example.org", method: "GET",
+			status: http.StatusNoContent, allowOrigin: "https://admin.example.org", methods: "GET, POST",
+		},
+		{
+			name: "regular expression origin", path: "/posts/1", origin: "http://localhost:3000", method: "GET",
+			status: http.StatusNoContent, allowOrigin: "http://localhost:3000", methods: "GET",
+		},
+		{name: "disallowed origin", path: "/posts", origin: "https://evil.com", method: "GET", status: http.StatusNoContent},
+		{name: "origin suffix is not a subdomain", path: "/posts", origin: "https://example.org.evil.com", method: "GET", status: http.StatusNoContent},
+		{name: "method routed but not allowed", path: "/posts/1", origin: "https://app.example.com", method: "DELETE", status: http.StatusNoContent},
+		{name: "method allowed but not routed", path: "/posts", origin: "https://app.example.com", method: "PUT", status: http.StatusNoContent},
+		{name: "disallowed header", path: "/posts", origin: "https://app.example.com", method: "POST", headers: "X-Secret", status: http.StatusNoContent},
+		{name: "unknown path", path: "/nope", origin: "https://app.example.com", method: "GET", status: http.StatusNotFound},
+	}
+
+	handler := newCORSHandler(cfg)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", tt.method)
+			if tt.headers != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.headers)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.allowOrigin)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Methods"); got != tt.methods {
+				t.Errorf("Access-Control-Allow-Methods = %q, want %q", got, tt.methods)
+			}
+			if tt.allowOrigin != "" && rec.Header().Get("Access-Control-Max-Age") != "600" {
+				t.Errorf("Access-Control-Max-Age = %q, want 600", rec.Header().Get("Access-Control-Max-Age"))
+			}
+		})
+	}
+}
+
+func TestCORSSimpleRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         CORSConfig
+		origin      string
+		allowOrigin string
+		credentials string
+	}{
+		{name: "no origin", cfg: CORSConfig{AllowedOrigins: []string{"*"}}},
+		{name: "any origin", cfg: CORSConfig{AllowedOrigins: []string{"*"}}, origin: "https://a.com", allowOrigin: "*"},
+		{
+			name: "any origin with credentials echoes the origin", cfg: CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+			origin: "https://a.com", allowOrigin: "https://a.com", credentials: "true",
+		},
+		{name: "disallowed origin", cfg: CORSConfig{AllowedOrigins: []string{"https://b.com"}}, origin: "https://a.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/posts", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+			newCORSHandler(tt.cfg).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("status = %d, want 200", rec.Code)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.allowOrigin)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != tt.credentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, tt.credentials)
+			}
+			if got := rec.Header().Values("Vary"); len(got) == 0 || got[0] != "Origin" {
+				t.Errorf("Vary = %q, want Origin", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Package middleware provides the HTTP middleware shipped with ThreadBolt.
+// Each middleware is an ordinary func(http.Handler) http.Handler and can be
+// used on any gorilla/mux router or subrouter; Default assembles the ones
+// enabled under the middleware section of config.yaml.
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Config holds the settings of every built-in middleware.
+type Config struct {
+	RequestID       RequestIDConfig
+	RealIP          RealIPConfig
+	AccessLog       AccessLogConfig
+	Recovery        RecoveryConfig
+	SecurityHeaders SecurityHeadersConfig
+	BodyLimit       BodyLimitConfig
+	Compression     CompressionConfig
+	Timeout         TimeoutConfig
+	CORS            CORSConfig
+}
+
+// LoadConfig reads the middleware and cors sections of v.
+func LoadConfig(v *viper.Viper) Config {
+	return Config{
+		RequestID: RequestIDConfig{
+			Enabled: v.GetBool("middleware.request_id.enabled"),
+			Header:  v.GetString("middleware.request_id.header"),
+		},
+		RealIP: RealIPConfig{
+			Enabled:        v.GetBool("middleware.real_ip.enabled"),
+			TrustedProxies: v.GetStringSlice("middleware.real_ip.trusted_proxies"),
+		},
+		AccessLog: AccessLogConfig{
+			Enabled: v.GetBool("middleware.access_log.enabled"),
+		},
+		Recovery: RecoveryConfig{
+			Enabled: v.GetBool("middleware.recovery.enabled"),
+		},
+		SecurityHeaders: SecurityHeadersConfig{
+			Enabled:               v.GetBool("middleware.security_headers.enabled"),
+			ContentTypeOptions:    v.GetString("middleware.security_headers.content_type_options"),
+			FrameOptions:          v.GetString("middleware.security_headers.frame_options"),
+			ReferrerPolicy:        v.GetString("middleware.security_headers.referrer_policy"),
+			ContentSecurityPolicy: v.GetString("middleware.security_headers.content_security_policy"),
+			HSTSMaxAge:            v.GetDuration("middleware.security_headers.hsts_max_age"),
+			TrustedProxies:        v.GetStringSlice("middleware.real_ip.trusted_proxies"),
+		},
+		BodyLimit: BodyLimitConfig{
+			Enabled:  v.GetBool("middleware.body_limit.enabled"),
+			MaxBytes: v.GetInt64("middleware.body_limit.max_bytes"),
+		},
+		Compression: CompressionConfig{
+			Enabled: v.GetBool("middleware.compression.enabled"),
+			Level:   v.GetInt("middleware.compression.level"),
+			MinSize: v.GetInt("middleware.compression.min_size"),
+		},
+		Timeout: TimeoutConfig{
+			Enabled:  v.GetBool("middleware.timeout.enabled"),
+			Duration: v.GetDuration("middleware.timeout.duration"),
+		},
+		CORS: CORSConfig{
+			Enabled:          v.GetBool("cors.enabled"),
+			AllowedOrigins:   v.GetStringSlice("cors.allowed_origins"),
+			AllowedMethods:   v.GetStringSlice("cors.allowed_methods"),
+			AllowedHeaders:   v.GetStringSlice("cors.allowed_headers"),
+			ExposedHeaders:   v.GetStringSlice("cors.exposed_headers"),
+			AllowCredentials: v.GetBool("cors.allow_credentials"),
+			MaxAge:           v.GetDuration("cors.max_age"),
+		},
+	}
+}
+
+// Default returns the enabled router middleware in the order they should wrap a
+// router: request ID first so every later log line can carry it, then real
+// IP, access logging around panic recovery so recovered panics are logged as
+// 500s, security headers, body limit, compression and finally the timeout
+// closest to the handler. CORS is not included as it must wrap the router
+// itself; see CORS.
+func Default(cfg Config, logger *slog.Logger) []func(http.Handler) http.Handler {
+	var chain []func(http.Handler) http.Handler
+
+	if cfg.RequestID.Enabled {
+		chain = append(chain, RequestID(cfg.RequestID))
+	}
+	if cfg.RealIP.Enabled {
+		chain = append(chain, RealIP(cfg.RealIP))
+	}
+	if cfg.AccessLog.Enabled {
+		chain = append(chain, AccessLog(logger))
+	}
+	if cfg.Recovery.Enabled {
+		chain = append(chain, Recovery(logger))
+	}
+	if cfg.SecurityHeaders.Enabled {
+		chain = append(chain, SecurityHeaders(cfg.SecurityHeaders))
+	}
+	if cfg.BodyLimit.Enabled {
+		chain = append(chain, BodyLimit(cfg.BodyLimit.MaxBytes))
+	}
+	if cfg.Compression.Enabled {
+		chain = append(chain, Compress(cfg.Compression))
+	}
+	if cfg.Timeout.Enabled {
+		chain = append(chain, Timeout(cfg.Timeout.Duration))
+	}
+
+	return chain
+}
+
+// AccessLogConfig configures AccessLog.
+type AccessLogConfig struct {
+	Enabled bool
+}
+
+// RecoveryConfig configures Recovery.
+type RecoveryConfig struct {
+	Enabled bool
+}
+
+// BodyLimitConfig configures BodyLimit.
+type BodyLimitConfig struct {
+	Enabled  bool
+	MaxBytes int64
+}
+
+// TimeoutConfig configures Timeout.
+type TimeoutConfig struct {
+	Enabled  bool
+	Duration time.Duration
+}
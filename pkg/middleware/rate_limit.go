@@ -0,0 +1,210 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ThreadBolt/threadbolt/pkg/problem"
+)
+
+// RateLimitAlgorithm selects how RateLimit counts requests.
+type RateLimitAlgorithm int
+
+const (
+	// TokenBucket allows bursts of up to Limit requests and refills at
+	// Limit requests per Window.
+	TokenBucket RateLimitAlgorithm = iota
+	// SlidingWindow allows Limit requests in any Window, weighting the
+	// previous fixed window by how much of it still overlaps the sliding one.
+	SlidingWindow
+)
+
+// KeyFunc identifies the client a request is counted against.
+type KeyFunc func(r *http.Request) string
+
+// RateLimitConfig configures RateLimit.
+type RateLimitConfig struct {
+	// Name separates the counters of limiters sharing a store, such as one
+	// per subrouter.
+	Name      string
+	Algorithm RateLimitAlgorithm
+	Limit     int
+	Window    time.Duration
+	// Key defaults to KeyByIP.
+	Key KeyFunc
+	// Store defaults to a new MemoryStore, which only limits requests seen
+	// by this process.
+	Store RateLimitStore
+}
+
+// RateLimit throttles requests per client, answering with 429 once a client
+// exceeds its limit. Every response carries RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers, and rejections also
+// carry Retry-After. If the store fails, the request is allowed and the
+// error logged, so an outage of a shared store does not take the API down.
+func RateLimit(cfg RateLimitConfig) func(http.Handler) http.Handler {
+	if cfg.Key == nil {
+		cfg.Key = KeyByIP
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+	limit := float64(cfg.Limit)
+	// The sliding window weighs in the previous window's count, so its
+	// state must outlive the window it was counted in.
+	ttl := cfg.Window
+	if cfg.Algorithm == SlidingWindow {
+		ttl = 2 * cfg.Window
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cfg.Limit <= 0 || cfg.Window <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := cfg.Name + ":" + cfg.Key(r)
+			var result rateLimitResult
+			err := cfg.Store.Update(r.Context(), key, ttl, func(state *RateLimitState) {
+				now := time.Now()
+				if cfg.Algorithm == SlidingWindow {
+					result = slidingWindow(state, limit, cfg.Window, now)
+				} else {
+					result = tokenBucket(state, limit, cfg.Window, now)
+				}
+			})
+			if err != nil {
+				slog.ErrorContext(r.Context(), "rate limit store failed", "key", key, "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(cfg.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(seconds(result.reset)))
+
+			if !result.allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(seconds(result.retryAfter)))
+				problem.Write(w, problem.New(http.StatusTooManyRequests, "rate limit exceeded"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// KeyByIP counts requests per client IP as determined by RealIP.
+func KeyByIP(r *http.Request) string {
+	return "ip:" + GetClientIP(r)
+}
+
+// KeyByHeader counts requests per value of the named header, such as an API
+// key, that valid accepts, falling back to the client IP for requests without
+// a valid one so that clients cannot evade the limit by sending made-up
+// values. Values are stored as SHA-256 hashes rather than in the clear.
+func KeyByHeader(name string, valid func(r *http.Request, value string) bool) KeyFunc {
+	return func(r *http.Request) string {
+		if value := r.Header.Get(name); value != "" && valid(r, value) {
+			sum := sha256.Sum256([]byte(value))
+			return "header:" + hex.EncodeToString(sum[:])
+		}
+		return KeyByIP(r)
+	}
+}
+
+// KeyByUser counts requests per authenticated user as returned by user,
+// falling back to the client IP for anonymous requests.
+func KeyByUser(user func(ctx context.Context) string) KeyFunc {
+	return func(r *http.Request) string {
+		if id := user(r.Context()); id != "" {
+			return "user:" + id
+		}
+		return KeyByIP(r)
+	}
+}
+
+type rateLimitResult struct {
+	allowed    bool
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+// tokenBucket keeps the tokens left in Value and the time of the last refill
+// in Updated.
+func tokenBucket(state *RateLimitState, limit float64, window time.Duration, now time.Time) rateLimitResult {
+	rate := limit / window.Seconds()
+
+	if state.Updated.IsZero() {
+		state.Value = limit
+	} else if elapsed := now.Sub(state.Updated).Seconds(); elapsed > 0 {
+		state.Value = math.Min(limit, state.Value+elapsed*rate)
+	}
+	state.Updated = now
+
+	result := rateLimitResult{allowed: state.Value >= 1}
+	if result.allowed {
+		state.Value--
+	} else {
+		result.retryAfter = time.Duration((1 - state.Value) / rate * float64(time.Second))
+	}
+	result.remaining = int(state.Value)
+	result.reset = time.Duration((limit - state.Value) / rate * float64(time.Second))
+	return result
+}
+
+// slidingWindow keeps the requests of the current fixed window in Value, those
+// of the window before in Previous, and the current window's start in
+// Updated.
+func slidingWindow(state *RateLimitState, limit float64, window time.Duration, now time.Time) rateLimitResult {
+	start := now.Truncate(window)
+	if !state.Updated.Equal(start) {
+		if state.Updated.Equal(start.Add(-window)) {
+			state.Previous = state.Value
+		} else {
+			state.Previous = 0
+		}
+		state.Value = 0
+		state.Updated = start
+	}
+
+	elapsed := now.Sub(start)
+	weight := 1 - float64(elapsed)/float64(window)
+	count := state.Previous*weight + state.Value
+
+	result := rateLimitResult{
+		allowed: count+1 <= limit,
+		reset:   window - elapsed,
+	}
+	if result.allowed {
+		state.Value++
+		count++
+	} else if state.Value+1 <= limit && state.Previous > 0 {
+		// The previous window's share shrinks as the window slides; wait
+		// until it leaves room for one more request.
+		needed := 1 - (limit-state.Value-1)/state.Previous
+		result.retryAfter = time.Duration(needed*float64(window)) - elapsed
+	} else {
+		// The current window is full on its own, so room only opens once
+		// it has become the previous window and slid far enough.
+		needed := 1 - (limit-1)/state.Value
+		result.retryAfter = window - elapsed + time.Duration(math.Max(0, needed)*float64(window))
+	}
+	result.remaining = int(math.Max(0, limit-count))
+	return result
+}
+
+func seconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
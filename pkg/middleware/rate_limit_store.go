@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RateLimitState is the counter RateLimit keeps per client. Its fields are
+// interpreted by the configured algorithm.
+type RateLimitState struct {
+	Value    float64
+	Previous float64
+	Updated  time.Time
+}
+
+// RateLimitStore holds rate limit counters. Update must apply fn atomically
+// to the state stored under key, passing the zero state for a key that is
+// unknown or has expired, and keep the result for at least ttl.
+type RateLimitStore interface {
+	Update(ctx context.Context, key string, ttl time.Duration, fn func(state *RateLimitState)) error
+}
+
+// MemoryStore keeps rate limit counters in process memory.
+type MemoryStore struct {
+	entries   map[string]*memoryEntry
+	nextSweep time.Time
+	mutex     sync.Mutex
+}
+
+type memoryEntry struct {
+	state   RateLimitState
+	expires time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*memoryEntry)}
+}
+
+func (s *MemoryStore) Update(ctx context.Context, key string, ttl time.Duration, fn func(state *RateLimitState)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	entry, ok := s.entries[key]
+	if !ok || now.After(entry.expires) {
+		entry = &memoryEntry{}
+		s.entries[key] = entry
+	}
+
+	fn(&entry.state)
+	entry.expires = now.Add(ttl)
+	return nil
+}
+
+// sweep drops expired entries at most once a minute so that clients that
+// stop sending requests do not accumulate.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+	for key, entry := range s.entries {
+		if now.After(entry.expires) {
+			delete(s.entries, key)
+		}
+	}
+	s.nextSweep = now.Add(time.Minute)
+}
+
+// GormStore keeps rate limit counters in the rate_limits table so that every
+// instance of an application shares them.
+type GormStore struct {
+	db *gorm.DB
+}
+
+type rateLimitEntry struct {
+	Key       string `gorm:"column:limit_key;primaryKey;size:255"`
+	Value     float64
+	Previous  float64
+	Updated   time.Time
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+func (rateLimitEntry) TableName() string {
+	return "rate_limits"
+}
+
+// NewGormStore returns a store backed by db, creating the rate_limits table
+// if it does not exist.
+func NewGormStore(db *gorm.DB) (*GormStore, error) {
+	if err := db.AutoMigrate(&rateLimitEntry{}); err != nil {
+		return nil, fmt.Errorf("failed to create rate_limits table: %w", err)
+	}
+	return &GormStore{db: db}, nil
+}
+
+func (s *GormStore) Update(ctx context.Context, key string, ttl time.Duration, fn func(state *RateLimitState)) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// Create an expired row first, as locking a row that does not exist
+		// yet locks nothing and concurrent first requests would both insert.
+		placeholder := rateLimitEntry{Key: key, ExpiresAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&placeholder).Error; err != nil {
+			return err
+		}
+
+		var entry rateLimitEntry
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("limit_key = ?", key).
+			First(&entry).Error
+		if err != nil {
+			return err
+		}
+
+		state := RateLimitState{}
+		if now.Before(entry.ExpiresAt) {
+			state = RateLimitState{Value: entry.Value, Previous: entry.Previous, Updated: entry.Updated}
+		}
+		fn(&state)
+
+		return tx.Model(&rateLimitEntry{}).
+			Where("limit_key = ?", key).
+			Updates(map[string]interface{}{
+				"value":      state.Value,
+				"previous":   state.Previous,
+				"updated":    state.Updated,
+				"expires_at": now.Add(ttl),
+			}).Error
+	})
+}
+
+// DeleteExpired removes counters that have expired, for example from a
+// periodic job.
+func (s *GormStore) DeleteExpired(ctx context.Context) error {
+	return s.db.WithContext(ctx).
+		Where("expires_at < ?", time.Now()).
+		Delete(&rateLimitEntry{}).Error
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var state RateLimitState
+
+	tests := []struct {
+		at        time.Duration
+		allowed   bool
+		remaining int
+	}{
+		{at: 0, allowed: true, remaining: 2},
+		{at: 0, allowed: true, remaining: 1},
+		{at: 0, allowed: true, remaining: 0},
+		{at: 0, allowed: false, remaining: 0},
+		// One token is refilled every 20s.
+		{at: 10 * time.Second, allowed: false, remaining: 0},
+		{at: 20 * time.Second, allowed: true, remaining: 0},
+		{at: 2 * time.Minute, allowed: true, remaining: 2},
+	}
+
+	for i, tt := range tests {
+		result := tokenBucket(&state, 3, time.Minute, start.Add(tt.at))
+		if result.allowed != tt.allowed || result.remaining != tt.remaining {
+			t.Errorf("request %d at %s: allowed = %v, remaining = %d, want %v, %d",
+				i, tt.at, result.allowed, result.remaining, tt.allowed, tt.remaining)
+		}
+		if !result.allowed && result.retryAfter <= 0 {
+			t.Errorf("request %d at %s: rejected without Retry-After", i, tt.at)
+		}
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var state RateLimitState
+
+	tests := []struct {
+		at      time.Duration
+		allowed bool
+	}{
+		{at: 50 * time.Second, allowed: true},
+		{at: 55 * time.Second, allowed: true},
+		{at: 59 * time.Second, allowed: false},
+		// The previous window still counts for 5/6 of its two requests.
+		{at: 70 * time.Second, allowed: false},
+		// Half of it has slid past, leaving room for one request.
+		{at: 90 * time.Second, allowed: true},
+		{at: 91 * time.Second, allowed: false},
+		// Two windows later nothing counts any more.
+		{at: 4 * time.Minute, allowed: true},
+	}
+
+	for i, tt := range tests {
+		result := slidingWindow(&state, 2, time.Minute, start.Add(tt.at))
+		if result.allowed != tt.allowed {
+			t.Errorf("request %d at %s: allowed = %v, want %v", i, tt.at, result.allowed, tt.allowed)
+		}
+		if !result.allowed && result.retryAfter <= 0 {
+			t.Errorf("request %d at %s: rejected without Retry-After", i, tt.at)
+		}
+	}
+}
+
+// keyRecorder is a MemoryStore that records the keys it is given.
+type keyRecorder struct {
+	*MemoryStore
+	keys []string
+}
+
+func (s *keyRecorder) Update(ctx context.Context, key string, ttl time.Duration, fn func(state *RateLimitState)) error {
+	s.keys = append(s.keys, key)
+	return s.MemoryStore.Update(ctx, key, ttl, fn)
+}
+
+func TestRateLimitKeyByHeader(t *testing.T) {
+	store := &keyRecorder{MemoryStore: NewMemoryStore()}
+	valid := func(r *http.Request, key string) bool { return strings.HasPrefix(key, "valid-") }
+	handler := RateLimit(RateLimitConfig{
+		Name:   "api",
+		Limit:  2,
+		Window: time.Minute,
+		Key:    KeyByHeader("X-API-Key", valid),
+		Store:  store,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		name   string
+		key    string
+		status int
+	}{
+		{name: "first valid key", key: "valid-a", status: http.StatusOK},
+		{name: "second request of a valid key", key: "valid-a", status: http.StatusOK},
+		{name: "valid key over its limit", key: "valid-a", status: http.StatusTooManyRequests},
+		{name: "other valid key", key: "valid-b", status: http.StatusOK},
+		{name: "made-up key counts against the IP", key: "made-up-1", status: http.StatusOK},
+		{name: "another made-up key shares the IP's count", key: "made-up-2", status: http.StatusOK},
+		{name: "IP over its limit", key: "made-up-3", status: http.StatusTooManyRequests},
+		{name: "no key", status: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if rec.Header().Get("RateLimit-Limit") != "2" {
+				t.Errorf("RateLimit-Limit = %q, want 2", rec.Header().Get("RateLimit-Limit"))
+			}
+			if tt.status == http.StatusTooManyRequests && rec.Header().Get("Retry-After") == "" {
+				t.Error("429 without Retry-After")
+			}
+		})
+	}
+
+	for _, key := range store.keys {
+		if strings.Contains(key, "valid-") || strings.Contains(key, "made-up") {
+			t.Errorf("store was given the raw key %q", key)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RealIPConfig configures RealIP.
+type RealIPConfig struct {
+	Enabled bool
+	// TrustedProxies lists the IPs or CIDR ranges of proxies whose
+	// X-Forwarded-For and X-Real-IP headers are believed. Forwarding headers
+	// from any other peer are ignored.
+	TrustedProxies []string
+}
+
+type (
+	clientIPKey struct{}
+	peerIPKey   struct{}
+)
+
+// RealIP determines the client's address, following X-Forwarded-For and
+// X-Real-IP only through trusted proxies. The result replaces r.RemoteAddr
+// and is available from GetClientIP.
+func RealIP(cfg RealIPConfig) func(http.Handler) http.Handler {
+	trusted := parseNetworks(cfg.TrustedProxies)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			peer := remoteHost(r.RemoteAddr)
+			ip := clientIP(r, trusted)
+			if ip != "" {
+				r.RemoteAddr = ip
+				ctx := context.WithValue(r.Context(), clientIPKey{}, ip)
+				r = r.WithContext(context.WithValue(ctx, peerIPKey{}, peer))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GetClientIP returns the client address stored by RealIP, falling back to
+// the host part of r.RemoteAddr.
+func GetClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return remoteHost(r.RemoteAddr)
+}
+
+// peerIP returns the address of the peer that sent r, which is the nearest
+// proxy rather than the client when RealIP has followed forwarding headers.
+func peerIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(peerIPKey{}).(string); ok {
+		return ip
+	}
+	return remoteHost(r.RemoteAddr)
+}
+
+func clientIP(r *http.Request, trusted []*net.IPNet) string {
+	peer := remoteHost(r.RemoteAddr)
+	if !isTrusted(peer, trusted) {
+		return peer
+	}
+
+	// Walk X-Forwarded-For from the nearest hop back, skipping our own
+	// proxies; the first untrusted address is the client.
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			if !isTrusted(hop, trusted) || i == 0 {
+				return hop
+			}
+		}
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+
+	return peer
+}
+
+func remoteHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+func isTrusted(ip string, trusted []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseNetworks(entries []string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			slog.Warn("ignoring invalid trusted proxy", "entry", entry, "error", err)
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
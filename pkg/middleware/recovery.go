@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/ThreadBolt/threadbolt/pkg/problem"
+)
+
+// Recovery turns a panic in a handler into a 500 response and logs the panic
+// value with its stack trace. http.ErrAbortHandler is re-raised so that the
+// server can abort the connection as intended.
+func Recovery(logger *slog.Logger) func(http.Handler) http.Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				logger.ErrorContext(r.Context(), "panic recovered",
+					"panic", recovered,
+					"method", r.Method,
+					"path", r.URL.Path,
+					"request_id", GetRequestID(r.Context()),
+					"stack", string(debug.Stack()),
+				)
+
+				p := problem.New(http.StatusInternalServerError, "")
+				p.RequestID = GetRequestID(r.Context())
+				problem.Write(w, p)
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
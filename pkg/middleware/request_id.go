@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+const defaultRequestIDHeader = "X-Request-ID"
+
+// RequestIDConfig configures RequestID.
+type RequestIDConfig struct {
+	Enabled bool
+	// Header carries the request ID in both directions. It defaults to
+	// X-Request-ID.
+	Header string
+}
+
+type requestIDKey struct{}
+
+// RequestID assigns every request an ID, reusing a well-formed ID sent by the
+// client or a proxy, stores it in the request context and echoes it in the
+// response.
+func RequestID(cfg RequestIDConfig) func(http.Handler) http.Handler {
+	header := cfg.Header
+	if header == "" {
+		header = defaultRequestIDHeader
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(header)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+
+			w.Header().Set(header, id)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+		})
+	}
+}
+
+// GetRequestID returns the request ID stored by RequestID, or "".
+func GetRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// validRequestID accepts short printable IDs, so that a client cannot inject
+// arbitrary data into logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
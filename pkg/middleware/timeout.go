@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ThreadBolt/threadbolt/pkg/problem"
+)
+
+// Timeout cancels the request context after d and answers 503 if the handler
+// has not returned by then. Handlers should watch r.Context().Done() to stop
+// work early; their later writes fail with http.ErrHandlerTimeout. Streaming
+// responses are not supported, as the response is buffered until the handler
+// returns.
+func Timeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if d <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			r = r.WithContext(ctx)
+
+			tw := &timeoutWriter{header: make(http.Header), status: http.StatusOK}
+			done := make(chan struct{})
+			panicked := make(chan interface{}, 1)
+			go func() {
+				defer func() {
+					if recovered := recover(); recovered != nil {
+						panicked <- recovered
+					}
+				}()
+				next.ServeHTTP(tw, r)
+				close(done)
+			}()
+
+			select {
+			case recovered := <-panicked:
+				// Re-raise on the serving goroutine, where Recovery and
+				// the server can handle it.
+				panic(recovered)
+			case <-done:
+				tw.mutex.Lock()
+				defer tw.mutex.Unlock()
+
+				header := w.Header()
+				for name, values := range tw.header {
+					header[name] = values
+				}
+				w.WriteHeader(tw.status)
+				w.Write(tw.body.Bytes())
+			case <-ctx.Done():
+				tw.mutex.Lock()
+				defer tw.mutex.Unlock()
+
+				tw.timedOut = true
+				p := problem.New(http.StatusServiceUnavailable, "request timed out")
+				p.RequestID = GetRequestID(r.Context())
+				problem.Write(w, p)
+			}
+		})
+	}
+}
+
+// timeoutWriter buffers a response until the handler returns, and discards
+// it once the request has timed out.
+type timeoutWriter struct {
+	mutex       sync.Mutex
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.status = status
+	tw.wroteHeader = true
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.wroteHeader = true
+	return tw.body.Write(b)
+}
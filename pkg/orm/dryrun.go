@@ -0,0 +1,157 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// DryRunMigrations returns the DDL statements AutoMigrate would execute for
+// the registered models, rendered for the connected database's dialect. The
+// live schema is still read so only the statements needed to reconcile it are
+// reported; nothing is written.
+func DryRunMigrations(db *gorm.DB) ([]string, error) {
+	session, recorder := recordingSession(db)
+
+	if err := session.AutoMigrate(RegisteredModels()...); err != nil {
+		return nil, fmt.Errorf("failed to plan migrations: %w", err)
+	}
+
+	return recorder.explain(db), nil
+}
+
+// MigrationPlan holds the statements that reconcile the database schema with
+// the registered models, and the statements that revert them.
+type MigrationPlan struct {
+	Up   []string `json:"up"`
+	Down []string `json:"down"`
+}
+
+// PlanMigration compares the registered models with the live schema. Down
+// statements drop the tables, columns and indexes that Up creates; changes
+// to existing column types are not reverted and need editing by hand.
+func PlanMigration(db *gorm.DB) (*MigrationPlan, error) {
+	up, err := DryRunMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	session, recorder := recordingSession(db)
+	migrator := session.Migrator()
+
+	models := RegisteredModels()
+	for i := len(models) - 1; i >= 0; i-- {
+		model := models[i]
+
+		if !migrator.HasTable(model) {
+			if err := migrator.DropTable(model); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to parse model %T: %w", model, err)
+		}
+
+		for name := range stmt.Schema.ParseIndexes() {
+			if !migrator.HasIndex(model, name) {
+				if err := migrator.DropIndex(model, name); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || field.IgnoreMigration {
+				continue
+			}
+			if !migrator.HasColumn(model, field.DBName) {
+				if err := migrator.DropColumn(model, field.DBName); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return &MigrationPlan{Up: up, Down: recorder.explain(db)}, nil
+}
+
+// recordingSession returns a session whose writes are captured by the
+// returned recorder instead of reaching the database.
+func recordingSession(db *gorm.DB) (*gorm.DB, *ddlRecorder) {
+	recorder := &ddlRecorder{pool: db.Statement.ConnPool}
+
+	// A new context forces the session to clone its statement, so swapping
+	// the pool does not leak back into db.
+	session := db.Session(&gorm.Session{Context: context.Background()})
+	session.Statement.ConnPool = recorder
+
+	return session, recorder
+}
+
+type recordedStatement struct {
+	sql  string
+	args []interface{}
+}
+
+// ddlRecorder is a gorm.ConnPool that forwards reads to the real pool and
+// records writes instead of executing them.
+type ddlRecorder struct {
+	pool       gorm.ConnPool
+	statements []recordedStatement
+	mutex      sync.Mutex
+}
+
+func (r *ddlRecorder) explain(db *gorm.DB) []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	statements := make([]string, 0, len(r.statements))
+	for _, statement := range r.statements {
+		statements = append(statements, db.Dialector.Explain(statement.sql, statement.args...))
+	}
+	return statements
+}
+
+func (r *ddlRecorder) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return r.pool.PrepareContext(ctx, query)
+}
+
+func (r *ddlRecorder) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.statements = append(r.statements, recordedStatement{sql: query, args: args})
+	return dryRunResult{}, nil
+}
+
+func (r *ddlRecorder) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return r.pool.QueryContext(ctx, query, args...)
+}
+
+func (r *ddlRecorder) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return r.pool.QueryRowContext(ctx, query, args...)
+}
+
+// BeginTx lets migrators that wrap DDL in transactions run against the
+// recorder; the returned pool records into the same statement list.
+func (r *ddlRecorder) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return &ddlRecorderTx{r}, nil
+}
+
+type ddlRecorderTx struct {
+	*ddlRecorder
+}
+
+func (ddlRecorderTx) Commit() error   { return nil }
+func (ddlRecorderTx) Rollback() error { return nil }
+
+type dryRunResult struct{}
+
+func (dryRunResult) LastInsertId() (int64, error) { return 0, nil }
+func (dryRunResult) RowsAffected() (int64, error) { return 0, nil }
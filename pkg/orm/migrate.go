@@ -0,0 +1,644 @@
+package orm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MigrationsDir is the conventional location of SQL migration files.
+const MigrationsDir = "migrations"
+
+// Migration files are named <version>_<name>.up.sql and <version>_<name>.down.sql,
+// where version is a sortable timestamp such as 20240102150405. A plain
+// <version>_<name>.sql file is treated as an up migration without a down step.
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([\w-]+?)(\.up|\.down)?\.sql$`)
+
+// Migration is a single versioned SQL migration read from disk.
+type Migration struct {
+	Version string
+	Name    string
+	UpSQL   string
+	DownSQL string
+	// Checksum covers both the up and the down SQL.
+	Checksum string
+}
+
+// MigrationStatus describes a migration and whether it has been applied.
+type MigrationStatus struct {
+	Version   string
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	// Modified is set when the file on disk no longer matches the checksum
+	// recorded when the migration was applied.
+	Modified bool
+	// Missing is set when an applied migration no longer exists on disk.
+	Missing bool
+}
+
+// SchemaMigration is a row of the schema_migrations tracking table.
+type SchemaMigration struct {
+	Version   string `gorm:"primaryKey;size:64"`
+	Name      string `gorm:"size:255"`
+	Checksum  string `gorm:"size:64"`
+	AppliedAt time.Time
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator applies and rolls back the SQL migrations in a directory.
+type Migrator struct {
+	db  *gorm.DB
+	dir string
+}
+
+// NewMigrator creates a migrator for the migration files in dir.
+func NewMigrator(db *gorm.DB, dir string) *Migrator {
+	return &Migrator{db: db, dir: dir}
+}
+
+// Up applies every pending migration in version order.
+func (m *Migrator) Up() ([]Migration, error) {
+	return m.migrateUp("")
+}
+
+// Down rolls back the n most recently applied migrations.
+func (m *Migrator) Down(n int) ([]Migration, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("number of migrations to roll back must be positive, got %d", n)
+	}
+
+	migrations, applied, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := appliedVersions(applied)
+	if n > len(versions) {
+		n = len(versions)
+	}
+
+	return m.rollback(migrations, versions[len(versions)-n:])
+}
+
+// Redo rolls back the most recently applied migration and applies it again.
+func (m *Migrator) Redo() (*Migration, error) {
+	rolledBack, err := m.Down(1)
+	if err != nil {
+		return nil, err
+	}
+	if len(rolledBack) == 0 {
+		return nil, errors.New("no applied migrations to redo")
+	}
+
+	target := rolledBack[0]
+	if err := m.apply(target); err != nil {
+		return nil, err
+	}
+
+	return &target, nil
+}
+
+// To migrates the database up or down so that version is the latest applied
+// migration. A version of "0" rolls back every migration.
+func (m *Migrator) To(version string) ([]Migration, error) {
+	migrations, applied, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := parseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	if target != 0 {
+		migration, ok := findMigration(migrations, version)
+		if !ok {
+			return nil, fmt.Errorf("migration version %s not found in %s", version, m.dir)
+		}
+		version = migration.Version
+	}
+
+	var newer []string
+	for _, v := range appliedVersions(applied) {
+		if versionNumber(v) > target {
+			newer = append(newer, v)
+		}
+	}
+
+	if len(newer) > 0 {
+		return m.rollback(migrations, newer)
+	}
+
+	if target == 0 {
+		return nil, nil
+	}
+
+	return m.migrateUp(version)
+}
+
+// Status reports every known migration, both on disk and in the tracking table.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	migrations, applied, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			status.Modified = record.Checksum != migration.Checksum
+		}
+		statuses = append(statuses, status)
+	}
+
+	for version, record := range applied {
+		if _, ok := findMigration(migrations, version); !ok {
+			appliedAt := record.AppliedAt
+			statuses = append(statuses, MigrationStatus{
+				Version:   version,
+				Name:      record.Name,
+				Applied:   true,
+				AppliedAt: &appliedAt,
+				Missing:   true,
+			})
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return versionNumber(statuses[i].Version) < versionNumber(statuses[j].Version)
+	})
+
+	return statuses, nil
+}
+
+// errPlanned rolls back the transaction Plan applies pending migrations in.
+var errPlanned = errors.New("migration planned")
+
+// Plan compares the registered models with the schema as it will be once the
+// pending migrations are applied, so that a new migration does not repeat
+// their statements. Dialects with transactional DDL apply the pending
+// migrations in a transaction that is rolled back; on MySQL the statements of
+// pending migrations are left out of the plan instead.
+func (m *Migrator) Plan() (*MigrationPlan, error) {
+	migrations, applied, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	if len(pending) == 0 {
+		return PlanMigration(m.db)
+	}
+
+	if !supportsTransactionalDDL(m.db) {
+		plan, err := PlanMigration(m.db)
+		if err != nil {
+			return nil, err
+		}
+		return withoutPending(plan, pending), nil
+	}
+
+	var plan *MigrationPlan
+	err = m.db.Transaction(func(tx *gorm.DB) error {
+		for _, migration := range pending {
+			for _, statement := range splitStatements(migration.UpSQL) {
+				if err := tx.Exec(statement).Error; err != nil {
+					return fmt.Errorf("pending migration %s_%s failed: %w", migration.Version, migration.Name, err)
+				}
+			}
+		}
+
+		var err error
+		if plan, err = PlanMigration(tx); err != nil {
+			return err
+		}
+		return errPlanned
+	})
+	if !errors.Is(err, errPlanned) {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// withoutPending removes the statements of pending migrations from plan.
+func withoutPending(plan *MigrationPlan, pending []Migration) *MigrationPlan {
+	up := make(map[string]bool)
+	down := make(map[string]bool)
+	for _, migration := range pending {
+		for _, statement := range splitStatements(migration.UpSQL) {
+			up[normalizeStatement(statement)] = true
+		}
+		for _, statement := range splitStatements(migration.DownSQL) {
+			down[normalizeStatement(statement)] = true
+		}
+	}
+
+	filter := func(statements []string, skip map[string]bool) []string {
+		var kept []string
+		for _, statement := range statements {
+			if !skip[normalizeStatement(statement)] {
+				kept = append(kept, statement)
+			}
+		}
+		return kept
+	}
+	return &MigrationPlan{Up: filter(plan.Up, up), Down: filter(plan.Down, down)}
+}
+
+// normalizeStatement collapses the whitespace in a statement for comparison.
+func normalizeStatement(statement string) string {
+	return strings.Join(strings.Fields(strings.TrimSuffix(strings.TrimSpace(statement), ";")), " ")
+}
+
+// migrateUp applies pending migrations up to and including target, or all
+// pending migrations when target is empty.
+func (m *Migrator) migrateUp(target string) ([]Migration, error) {
+	migrations, applied, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyChecksums(migrations, applied); err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range migrations {
+		if target != "" && versionNumber(migration.Version) > versionNumber(target) {
+			break
+		}
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := m.apply(migration); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// apply runs the up step of a migration and records it as applied.
+func (m *Migrator) apply(migration Migration) error {
+	err := m.run(migration.UpSQL, func(tx *gorm.DB) error {
+		return tx.Create(&SchemaMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Checksum:  migration.Checksum,
+			AppliedAt: time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %s_%s failed: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// rollback runs the down step of each version, newest first.
+func (m *Migrator) rollback(migrations []Migration, versions []string) ([]Migration, error) {
+	sort.Slice(versions, func(i, j int) bool {
+		return versionNumber(versions[i]) > versionNumber(versions[j])
+	})
+
+	var done []Migration
+	for _, version := range versions {
+		migration, ok := findMigration(migrations, version)
+		if !ok {
+			return done, fmt.Errorf("cannot roll back migration %s: file not found in %s", version, m.dir)
+		}
+		if strings.TrimSpace(migration.DownSQL) == "" {
+			return done, fmt.Errorf("cannot roll back migration %s_%s: no down migration", migration.Version, migration.Name)
+		}
+
+		err := m.run(migration.DownSQL, func(tx *gorm.DB) error {
+			return tx.Delete(&SchemaMigration{}, "version = ?", migration.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("rollback of %s_%s failed: %w", migration.Version, migration.Name, err)
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// run executes the statements in script and then record. Dialects with
+// transactional DDL run both inside a single transaction.
+func (m *Migrator) run(script string, record func(tx *gorm.DB) error) error {
+	statements := splitStatements(script)
+
+	execute := func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return record(tx)
+	}
+
+	if !supportsTransactionalDDL(m.db) {
+		return execute(m.db)
+	}
+
+	return m.db.Transaction(execute)
+}
+
+func (m *Migrator) load() ([]Migration, map[string]SchemaMigration, error) {
+	if err := m.db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	migrations, err := LoadMigrations(m.dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var records []SchemaMigration
+	if err := m.db.Find(&records).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	applied := make(map[string]SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+
+	return migrations, applied, nil
+}
+
+// LoadMigrations reads and pairs the up and down migration files in dir,
+// sorted by version.
+func LoadMigrations(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read migration files: %w", err)
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		version, name, direction := match[1], match[2], match[3]
+		number, err := parseVersion(version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration %s: %w", entry.Name(), err)
+		}
+		migration, ok := byVersion[number]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[number] = migration
+		} else if migration.Name != name || migration.Version != version {
+			return nil, fmt.Errorf("duplicate migration version %s (%s_%s and %s_%s)", version, migration.Version, migration.Name, version, name)
+		}
+
+		if direction == ".down" {
+			migration.DownSQL = string(content)
+		} else {
+			if migration.UpSQL != "" {
+				return nil, fmt.Errorf("duplicate up migration for version %s", version)
+			}
+			migration.UpSQL = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.UpSQL == "" {
+			return nil, fmt.Errorf("migration %s_%s has no up migration", migration.Version, migration.Name)
+		}
+		migration.Checksum = checksum(migration.UpSQL, migration.DownSQL)
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return versionNumber(migrations[i].Version) < versionNumber(migrations[j].Version)
+	})
+
+	return migrations, nil
+}
+
+// NextVersion returns the version of a new migration in dir: the current UTC
+// time, such as 20240102150405, or one past the latest version in dir if that
+// is not earlier, so that versions stay unique and ordered even for
+// migrations generated within the same second.
+func NextVersion(dir string) (string, error) {
+	version, err := parseVersion(time.Now().UTC().Format("20060102150405"))
+	if err != nil {
+		return "", err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read migration files: %w", err)
+	}
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		latest, err := parseVersion(match[1])
+		if err != nil {
+			return "", fmt.Errorf("invalid migration %s: %w", entry.Name(), err)
+		}
+		if latest >= version {
+			version = latest + 1
+		}
+	}
+
+	return strconv.FormatUint(version, 10), nil
+}
+
+func verifyChecksums(migrations []Migration, applied map[string]SchemaMigration) error {
+	for _, migration := range migrations {
+		record, ok := applied[migration.Version]
+		if ok && record.Checksum != migration.Checksum {
+			return fmt.Errorf("migration %s_%s has been modified since it was applied", migration.Version, migration.Name)
+		}
+	}
+	return nil
+}
+
+func appliedVersions(applied map[string]SchemaMigration) []string {
+	versions := make([]string, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versionNumber(versions[i]) < versionNumber(versions[j])
+	})
+	return versions
+}
+
+// findMigration finds a migration by version, comparing versions as numbers
+// so that "5" finds 0005_x.
+func findMigration(migrations []Migration, version string) (Migration, bool) {
+	number, err := parseVersion(version)
+	if err != nil {
+		return Migration{}, false
+	}
+	for _, migration := range migrations {
+		if versionNumber(migration.Version) == number {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// parseVersion parses a migration version, a number such as 20240102150405.
+func parseVersion(version string) (uint64, error) {
+	number, err := strconv.ParseUint(version, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid migration version %q: versions must be numbers such as 20240102150405", version)
+	}
+	return number, nil
+}
+
+// versionNumber returns the number of a version that has already been
+// validated, such as one read from disk or from schema_migrations.
+func versionNumber(version string) uint64 {
+	number, _ := parseVersion(version)
+	return number
+}
+
+func checksum(up, down string) string {
+	hash := sha256.New()
+	hash.Write([]byte(up))
+	hash.Write([]byte{0})
+	hash.Write([]byte(down))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// supportsTransactionalDDL reports whether schema changes can be rolled back.
+// MySQL implicitly commits around DDL statements, so wrapping them in a
+// transaction would only give a false sense of atomicity.
+func supportsTransactionalDDL(db *gorm.DB) bool {
+	return db.Dialector.Name() != "mysql"
+}
+
+// splitStatements splits a SQL script on semicolons that are not inside
+// quotes, comments or PostgreSQL dollar-quoted bodies.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+
+	flush := func() {
+		statement := strings.TrimSpace(current.String())
+		if statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := i + 1
+			for end < len(script) && script[end] != c {
+				end++
+			}
+			current.WriteString(script[i:min(end+1, len(script))])
+			i = end
+			continue
+
+		case c == '-' && i+1 < len(script) && script[i+1] == '-':
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end
+				current.WriteByte('\n')
+			}
+			continue
+
+		case c == '/' && i+1 < len(script) && script[i+1] == '*':
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 3
+			}
+			continue
+
+		case c == '$':
+			if tag := dollarQuoteTag(script[i:]); tag != "" {
+				end := strings.Index(script[i+len(tag):], tag)
+				if end < 0 {
+					current.WriteString(script[i:])
+					i = len(script)
+				} else {
+					stop := i + len(tag) + end + len(tag)
+					current.WriteString(script[i:stop])
+					i = stop - 1
+				}
+				continue
+			}
+
+		case c == ';':
+			flush()
+			continue
+		}
+
+		current.WriteByte(c)
+	}
+	flush()
+
+	return statements
+}
+
+// dollarQuoteTag returns the opening tag ($$ or $name$) at the start of s.
+func dollarQuoteTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '$':
+			return s[:i+1]
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || (i > 1 && c >= '0' && c <= '9'):
+			continue
+		default:
+			return ""
+		}
+	}
+	return ""
+}
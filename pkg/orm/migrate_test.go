@@ -0,0 +1,293 @@
+package orm
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{"empty", "", nil},
+		{"single without semicolon", "SELECT 1", []string{"SELECT 1"}},
+		{"several", "CREATE TABLE a (id int);\nCREATE TABLE b (id int);", []string{"CREATE TABLE a (id int)", "CREATE TABLE b (id int)"}},
+		{"semicolon in string", "INSERT INTO a VALUES ('x;y');", []string{"INSERT INTO a VALUES ('x;y')"}},
+		{"semicolon in quoted identifier", `SELECT "a;b" FROM c;`, []string{`SELECT "a;b" FROM c`}},
+		{"line comment", "-- drop it; later\nSELECT 1;", []string{"SELECT 1"}},
+		{"block comment", "/* a; b */ SELECT 1;", []string{"SELECT 1"}},
+		{"dollar quoted body", "CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql;", []string{"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql"}},
+		{"tagged dollar quote", "DO $body$ BEGIN; END $body$;", []string{"DO $body$ BEGIN; END $body$"}},
+		{"only comments", "-- Write your SQL here\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements(%q) = %q, want %q", tt.script, got, tt.want)
+			}
+		})
+	}
+}
+
+func writeMigrations(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		versions []string
+		wantErr  bool
+	}{
+		{
+			name: "versions are ordered as numbers",
+			files: map[string]string{
+				"20240102150405_c.up.sql": "SELECT 3;",
+				"20240101_b.up.sql":       "SELECT 2;",
+				"10_d.sql":                "SELECT 4;",
+				"5_a.up.sql":              "SELECT 1;",
+				"5_a.down.sql":            "SELECT 0;",
+				"notes.txt":               "ignored",
+			},
+			versions: []string{"5", "10", "20240101", "20240102150405"},
+		},
+		{
+			name:    "equal numbers are duplicates",
+			files:   map[string]string{"5_a.up.sql": "SELECT 1;", "05_b.up.sql": "SELECT 2;"},
+			wantErr: true,
+		},
+		{
+			name:    "versions must fit in 64 bits",
+			files:   map[string]string{"99999999999999999999_a.up.sql": "SELECT 1;"},
+			wantErr: true,
+		},
+		{
+			name:    "down without up",
+			files:   map[string]string{"1_a.down.sql": "SELECT 1;"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeMigrations(t, dir, tt.files)
+
+			migrations, err := LoadMigrations(dir)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var versions []string
+			for _, migration := range migrations {
+				versions = append(versions, migration.Version)
+			}
+			if !reflect.DeepEqual(versions, tt.versions) {
+				t.Errorf("versions = %v, want %v", versions, tt.versions)
+			}
+		})
+	}
+}
+
+func newTestMigrator(t *testing.T, files map[string]string) (*Migrator, *gorm.DB, string) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection to :memory: opens a new, empty database.
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	dir := t.TempDir()
+	writeMigrations(t, dir, files)
+	return NewMigrator(db, dir), db, dir
+}
+
+var testMigrations = map[string]string{
+	"1_create_a.up.sql":   "CREATE TABLE a (id integer);",
+	"1_create_a.down.sql": "DROP TABLE a;",
+	"3_create_c.up.sql":   "CREATE TABLE c (id integer);",
+	"3_create_c.down.sql": "DROP TABLE c;",
+}
+
+func appliedList(t *testing.T, m *Migrator) []string {
+	t.Helper()
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var applied []string
+	for _, status := range statuses {
+		if status.Applied {
+			applied = append(applied, status.Version)
+		}
+	}
+	return applied
+}
+
+func TestMigratorTo(t *testing.T) {
+	m, db, _ := newTestMigrator(t, testMigrations)
+
+	tests := []struct {
+		version string
+		applied []string
+		wantErr bool
+	}{
+		{version: "3", applied: []string{"1", "3"}},
+		{version: "1", applied: []string{"1"}},
+		{version: "0", applied: nil},
+		{version: "03", applied: []string{"1", "3"}},
+		{version: "2", applied: []string{"1", "3"}, wantErr: true},
+		{version: "abc", applied: []string{"1", "3"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			_, err := m.To(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("To(%q) error = %v, wantErr %v", tt.version, err, tt.wantErr)
+			}
+			if got := appliedList(t, m); !reflect.DeepEqual(got, tt.applied) {
+				t.Errorf("applied = %v, want %v", got, tt.applied)
+			}
+		})
+	}
+
+	if !db.Migrator().HasTable("c") {
+		t.Error("table c was not recreated")
+	}
+}
+
+func TestMigratorRedoOnlyReappliesLastMigration(t *testing.T) {
+	m, _, dir := newTestMigrator(t, testMigrations)
+	if _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	// An older migration added after the newer one was applied stays
+	// pending.
+	writeMigrations(t, dir, map[string]string{"2_create_b.up.sql": "CREATE TABLE b (id integer);"})
+
+	migration, err := m.Redo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migration.Version != "3" {
+		t.Errorf("redid %s, want 3", migration.Version)
+	}
+	if got, want := appliedList(t, m), []string{"1", "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("applied = %v, want %v", got, want)
+	}
+}
+
+func TestMigratorDetectsModifiedDownMigration(t *testing.T) {
+	m, _, dir := newTestMigrator(t, testMigrations)
+	if _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	writeMigrations(t, dir, map[string]string{"1_create_a.down.sql": "DROP TABLE IF EXISTS a;"})
+
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if want := status.Version == "1"; status.Modified != want {
+			t.Errorf("migration %s: Modified = %v, want %v", status.Version, status.Modified, want)
+		}
+	}
+}
+
+type planWidget struct {
+	ID   uint
+	Name string
+}
+
+type planGadget struct {
+	ID   uint
+	Name string
+}
+
+func TestMigratorPlanIncludesPendingMigrations(t *testing.T) {
+	RegisterModel(&planWidget{}, &planGadget{})
+
+	m, db, dir := newTestMigrator(t, nil)
+	plan, err := m.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Up) == 0 {
+		t.Fatal("expected statements creating both tables")
+	}
+
+	// A migration creating one of the tables is pending, so only the other
+	// one is planned.
+	writeMigrations(t, dir, map[string]string{
+		"1_create_plan_widgets.up.sql":   strings.Join(plan.Up[:1], ";\n") + ";",
+		"1_create_plan_widgets.down.sql": "DROP TABLE plan_widgets;",
+	})
+	plan, err = m.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, statement := range plan.Up {
+		if strings.Contains(statement, "plan_widgets") {
+			t.Errorf("planned %q again", statement)
+		}
+	}
+	if len(plan.Up) == 0 {
+		t.Error("expected statements creating plan_gadgets")
+	}
+
+	if db.Migrator().HasTable("plan_widgets") {
+		t.Error("planning applied the pending migration")
+	}
+}
+
+func TestWithoutPending(t *testing.T) {
+	plan := &MigrationPlan{
+		Up:   []string{"CREATE TABLE `a` (`id` int)", "CREATE TABLE `b` (`id` int)"},
+		Down: []string{"DROP TABLE `b`", "DROP TABLE `a`"},
+	}
+	pending := []Migration{{
+		UpSQL:   "CREATE TABLE `a`\n  (`id` int);",
+		DownSQL: "DROP TABLE `a`;",
+	}}
+
+	got := withoutPending(plan, pending)
+	if want := []string{"CREATE TABLE `b` (`id` int)"}; !reflect.DeepEqual(got.Up, want) {
+		t.Errorf("Up = %q, want %q", got.Up, want)
+	}
+	if want := []string{"DROP TABLE `b`"}; !reflect.DeepEqual(got.Down, want) {
+		t.Errorf("Down = %q, want %q", got.Down, want)
+	}
+}
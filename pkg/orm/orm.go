@@ -2,19 +2,31 @@ package orm
 
 import (
 	"fmt"
-	"io/fs"
-	"path/filepath"
-	"strings"
+	"log/slog"
 
 	"github.com/spf13/viper"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+
+	"github.com/ThreadBolt/threadbolt/pkg/log"
 )
 
+// Initialize opens the configured database, logging SQL with a logger built
+// from the logging configuration.
 func Initialize(config *viper.Viper) (*gorm.DB, error) {
+	logger, err := log.FromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return InitializeWithLogger(config, logger)
+}
+
+// InitializeWithLogger opens the configured database and routes GORM's SQL
+// logs to logger.
+func InitializeWithLogger(config *viper.Viper, logger *slog.Logger) (*gorm.DB, error) {
 	driver := config.GetString("database.driver")
 
 	var dialector gorm.Dialector
@@ -53,13 +65,8 @@ func Initialize(config *viper.Viper) (*gorm.DB, error) {
 	}
 
 	// Configure GORM
-	gormConfig := &gorm.Config{}
-
-	// Set log level based on environment
-	if config.GetString("environment") == "production" {
-		gormConfig.Logger = logger.Default.LogMode(logger.Silent)
-	} else {
-		gormConfig.Logger = logger.Default.LogMode(logger.Info)
+	gormConfig := &gorm.Config{
+		Logger: log.NewGormLogger(logger),
 	}
 
 	db, err := gorm.Open(dialector, gormConfig)
@@ -70,50 +77,34 @@ func Initialize(config *viper.Viper) (*gorm.DB, error) {
 	return db, nil
 }
 
+// RunMigrations applies the pending SQL migrations from the migrations
+// directory and then auto-migrates every registered model. Running the SQL
+// migrations first lets migrations generated with "generate migration --auto"
+// create the schema, leaving AutoMigrate with nothing to do.
 func RunMigrations(db *gorm.DB) error {
-	// Auto-migrate all models in the models directory
-	modelFiles, err := filepath.Glob("models/*.go")
-	if err != nil {
-		return fmt.Errorf("failed to find model files: %w", err)
+	if err := runCustomMigrations(db); err != nil {
+		return err
 	}
 
-	if len(modelFiles) == 0 {
-		fmt.Println("No model files found for migration")
+	models := RegisteredModels()
+	if len(models) == 0 {
+		slog.Info("no registered models found for migration")
 		return nil
 	}
 
-	// For now, we'll just run auto-migrate
-	// In a real implementation, you'd parse the model files and register them
-	fmt.Printf("Found %d model files for migration\n", len(modelFiles))
+	if err := db.AutoMigrate(models...); err != nil {
+		return fmt.Errorf("failed to auto-migrate models: %w", err)
+	}
+	slog.Info("auto-migrated models", "count", len(models))
 
-	// Run custom migrations from migrations directory
-	return runCustomMigrations(db)
+	return nil
 }
 
 func runCustomMigrations(db *gorm.DB) error {
-	migrationFiles := []string{}
-
-	err := filepath.WalkDir("migrations", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if !d.IsDir() && strings.HasSuffix(path, ".sql") {
-			migrationFiles = append(migrationFiles, path)
-		}
-
-		return nil
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to read migration files: %w", err)
+	applied, err := NewMigrator(db, MigrationsDir).Up()
+	for _, migration := range applied {
+		slog.Info("applied migration", "version", migration.Version, "name", migration.Name)
 	}
 
-	for _, file := range migrationFiles {
-		fmt.Printf("Running migration: %s\n", file)
-		// Here you would execute the SQL file
-		// This is a simplified version
-	}
-
-	return nil
+	return err
 }
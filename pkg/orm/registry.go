@@ -0,0 +1,43 @@
+package orm
+
+import (
+	"reflect"
+	"sync"
+)
+
+var (
+	registeredModels []interface{}
+	registryMutex    sync.RWMutex
+)
+
+// RegisterModel adds models to the set migrated by RunMigrations. Models are
+// usually registered from the generated models/registry.go. Registering the
+// same type twice has no effect.
+func RegisterModel(models ...interface{}) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	for _, model := range models {
+		if isRegistered(reflect.TypeOf(model)) {
+			continue
+		}
+		registeredModels = append(registeredModels, model)
+	}
+}
+
+// RegisteredModels returns the registered models in registration order.
+func RegisteredModels() []interface{} {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	return append([]interface{}(nil), registeredModels...)
+}
+
+func isRegistered(modelType reflect.Type) bool {
+	for _, model := range registeredModels {
+		if reflect.TypeOf(model) == modelType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ThreadBolt/threadbolt/pkg/problem"
+)
+
+// Meta describes the page of results a list response holds.
+type Meta struct {
+	// Page is omitted for cursor pagination.
+	Page       int    `json:"page,omitempty"`
+	PerPage    int    `json:"per_page"`
+	Total      int64  `json:"total"`
+	TotalPages int    `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	cursor bool
+}
+
+// Paginate loads the page of T requested by p into dst, applying its filters
+// and sort to db, and returns the page's metadata. Cursor pages continue
+// after the last row of the previous page, so rows inserted meanwhile do not
+// shift them.
+func Paginate[T any](db *gorm.DB, p *Params, dst *[]T) (*Meta, error) {
+	base := db.Model(new(T)).Scopes(p.Filter).Session(&gorm.Session{})
+
+	var total int64
+	if err := base.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	meta := &Meta{
+		PerPage:    p.PerPage,
+		Total:      total,
+		TotalPages: int((total + int64(p.PerPage) - 1) / int64(p.PerPage)),
+		cursor:     p.UseCursor,
+	}
+
+	if !p.UseCursor {
+		meta.Page = p.Page
+		err := base.Scopes(p.Order).Offset((p.Page - 1) * p.PerPage).Limit(p.PerPage).Find(dst).Error
+		if *dst == nil {
+			*dst = []T{}
+		}
+		return meta, err
+	}
+
+	err := base.Scopes(p.Order, p.after).Limit(p.PerPage + 1).Find(dst).Error
+	if *dst == nil {
+		*dst = []T{}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*dst) > p.PerPage {
+		*dst = (*dst)[:p.PerPage]
+		meta.NextCursor, err = p.encodeCursor(db, &(*dst)[p.PerPage-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return meta, nil
+}
+
+// Links returns a Link header value pointing at the first, previous, next
+// and last pages relative to u, the URL of the current page.
+func (m *Meta) Links(u *url.URL) string {
+	var links []string
+	link := func(rel string, set func(url.Values)) {
+		values := u.Query()
+		set(values)
+		links = append(links, fmt.Sprintf(`<%s?%s>; rel="%s"`, u.Path, values.Encode(), rel))
+	}
+
+	if m.cursor {
+		link("first", func(v url.Values) { v.Set("cursor", "") })
+		if m.NextCursor != "" {
+			link("next", func(v url.Values) { v.Set("cursor", m.NextCursor) })
+		}
+		return strings.Join(links, ", ")
+	}
+
+	page := func(n int) func(url.Values) {
+		return func(v url.Values) { v.Set("page", strconv.Itoa(n)) }
+	}
+	last := m.TotalPages
+	if last < 1 {
+		last = 1
+	}
+	link("first", page(1))
+	if m.Page > 1 {
+		link("prev", page(min(m.Page-1, last)))
+	}
+	if m.Page < last {
+		link("next", page(m.Page+1))
+	}
+	link("last", page(last))
+	return strings.Join(links, ", ")
+}
+
+// after is a GORM scope restricting the query to rows ordered after the
+// request's cursor.
+func (p *Params) after(db *gorm.DB) *gorm.DB {
+	if p.Cursor == "" {
+		return db
+	}
+	s, err := modelSchema(db)
+	if err != nil {
+		db.AddError(err)
+		return db
+	}
+	ordering, err := p.ordering(s)
+	if err != nil {
+		db.AddError(err)
+		return db
+	}
+
+	invalid := problem.New(http.StatusBadRequest, "invalid query parameters")
+	invalid.Errors = map[string][]string{"cursor": {"is not a valid cursor for this query"}}
+
+	data, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+	var raw []json.RawMessage
+	if err == nil {
+		err = json.Unmarshal(data, &raw)
+	}
+	if err != nil || len(raw) != len(ordering) {
+		db.AddError(invalid)
+		return db
+	}
+
+	values := make([]interface{}, len(ordering))
+	for i, o := range ordering {
+		value := reflect.New(o.field.FieldType)
+		if err := json.Unmarshal(raw[i], value.Interface()); err != nil {
+			db.AddError(invalid)
+			return db
+		}
+		values[i] = value.Elem().Interface()
+	}
+
+	// Rows after the cursor sort after it on the first column, or tie on it
+	// and sort after it on the next, and so on.
+	var alternatives []string
+	var vars []interface{}
+	for i, o := range ordering {
+		var conds []string
+		for j := 0; j < i; j++ {
+			conds = append(conds, quote(db, s.Table, ordering[j])+" = ?")
+			vars = append(vars, values[j])
+		}
+		op := " > ?"
+		if o.Desc {
+			op = " < ?"
+		}
+		conds = append(conds, quote(db, s.Table, o)+op)
+		vars = append(vars, values[i])
+		alternatives = append(alternatives, "("+strings.Join(conds, " AND ")+")")
+	}
+	return db.Where("("+strings.Join(alternatives, " OR ")+")", vars...)
+}
+
+// encodeCursor returns the cursor of the page ending with item: its values
+// of the ordering columns.
+func (p *Params) encodeCursor(db *gorm.DB, item interface{}) (string, error) {
+	s, err := parseSchema(db, item)
+	if err != nil {
+		return "", err
+	}
+	ordering, err := p.ordering(s)
+	if err != nil {
+		return "", err
+	}
+
+	values := make([]interface{}, len(ordering))
+	row := reflect.ValueOf(item).Elem()
+	for i, o := range ordering {
+		values[i] = o.field.ReflectValueOf(db.Statement.Context, row).Interface()
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func quote(db *gorm.DB, table string, o orderField) string {
+	return db.Statement.Quote(clause.Column{Table: table, Name: o.field.DBName})
+}
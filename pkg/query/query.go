@@ -0,0 +1,183 @@
+// Package query parses the pagination, sorting and filtering parameters of
+// list endpoints and applies them to GORM queries:
+//
+//	GET /posts?page=2&per_page=20&sort=-created_at,title&filter[status]=published
+//	GET /posts?cursor=&per_page=20&filter[views][gte]=100
+//
+// Only the columns whitelisted in Options may be sorted or filtered by.
+package query
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ThreadBolt/threadbolt/pkg/problem"
+)
+
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+)
+
+// Options whitelists the columns, by database name, that a list endpoint may
+// be sorted and filtered by.
+type Options struct {
+	Sortable   []string
+	Filterable []string
+	// DefaultSort applies to requests without a sort parameter, for example
+	// "-created_at". Results are always ordered by primary key last.
+	DefaultSort string
+	// PerPage defaults to DefaultPerPage; requests may not ask for more than
+	// MaxPerPage, which defaults to the package's MaxPerPage.
+	PerPage    int
+	MaxPerPage int
+}
+
+// Operator compares a filtered column with the filter's value.
+type Operator string
+
+const (
+	Eq   Operator = "eq"
+	Ne   Operator = "ne"
+	Gt   Operator = "gt"
+	Gte  Operator = "gte"
+	Lt   Operator = "lt"
+	Lte  Operator = "lte"
+	In   Operator = "in"
+	Like Operator = "like"
+)
+
+var operators = map[Operator]bool{Eq: true, Ne: true, Gt: true, Gte: true, Lt: true, Lte: true, In: true, Like: true}
+
+// Sort orders results by Column.
+type Sort struct {
+	Column string
+	Desc   bool
+}
+
+// Filter restricts results to rows whose Column compares to Value by
+// Operator. The values of In are separated by commas.
+type Filter struct {
+	Column   string
+	Operator Operator
+	Value    string
+}
+
+// Params are the parsed list parameters of a request.
+type Params struct {
+	Page    int
+	PerPage int
+	// UseCursor is set when the request asked for cursor pagination with a
+	// cursor parameter, which is empty for the first page.
+	UseCursor bool
+	Cursor    string
+	Sort      []Sort
+	Filters   []Filter
+}
+
+var filterKey = regexp.MustCompile(`^filter\[([^\[\]]+)\](?:\[([^\[\]]+)\])?$`)
+
+// Parse reads the list parameters of r. Parameters that are malformed or
+// name columns outside opts' whitelist are reported as a 400 problem.
+func Parse(r *http.Request, opts Options) (*Params, error) {
+	values := r.URL.Query()
+	invalid := make(map[string][]string)
+
+	params := &Params{Page: 1, PerPage: opts.PerPage}
+	if params.PerPage <= 0 {
+		params.PerPage = DefaultPerPage
+	}
+	maxPerPage := opts.MaxPerPage
+	if maxPerPage <= 0 {
+		maxPerPage = MaxPerPage
+	}
+
+	if raw := values.Get("page"); raw != "" {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			invalid["page"] = append(invalid["page"], "must be a positive integer")
+		}
+		params.Page = page
+	}
+
+	if raw := values.Get("per_page"); raw != "" {
+		perPage, err := strconv.Atoi(raw)
+		switch {
+		case err != nil || perPage < 1:
+			invalid["per_page"] = append(invalid["per_page"], "must be a positive integer")
+		case perPage > maxPerPage:
+			invalid["per_page"] = append(invalid["per_page"], fmt.Sprintf("must be at most %d", maxPerPage))
+		}
+		params.PerPage = perPage
+	}
+
+	if _, ok := values["cursor"]; ok {
+		params.UseCursor = true
+		params.Cursor = values.Get("cursor")
+		if values.Has("page") {
+			invalid["page"] = append(invalid["page"], "cannot be combined with cursor")
+		}
+	}
+
+	sort := opts.DefaultSort
+	if values.Has("sort") {
+		sort = values.Get("sort")
+	}
+	for _, name := range strings.Split(sort, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		s := Sort{Column: strings.TrimPrefix(name, "-"), Desc: strings.HasPrefix(name, "-")}
+		if !contains(opts.Sortable, s.Column) {
+			invalid["sort"] = append(invalid["sort"], fmt.Sprintf("cannot sort by %s", s.Column))
+			continue
+		}
+		params.Sort = append(params.Sort, s)
+	}
+
+	for key, vals := range values {
+		match := filterKey.FindStringSubmatch(key)
+		if match == nil {
+			if strings.HasPrefix(key, "filter") {
+				invalid[key] = append(invalid[key], "must be of the form filter[column] or filter[column][operator]")
+			}
+			continue
+		}
+
+		column, op := match[1], Operator(match[2])
+		if op == "" {
+			op = Eq
+		}
+		if !contains(opts.Filterable, column) {
+			invalid[key] = append(invalid[key], fmt.Sprintf("cannot filter by %s", column))
+			continue
+		}
+		if !operators[op] {
+			invalid[key] = append(invalid[key], fmt.Sprintf("unknown operator %s", op))
+			continue
+		}
+		for _, value := range vals {
+			params.Filters = append(params.Filters, Filter{Column: column, Operator: op, Value: value})
+		}
+	}
+
+	if len(invalid) > 0 {
+		p := problem.New(http.StatusBadRequest, "invalid query parameters")
+		p.Errors = invalid
+		return nil, p
+	}
+	return params, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,223 @@
+package query
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/ThreadBolt/threadbolt/pkg/problem"
+)
+
+var testOptions = Options{
+	Sortable:    []string{"score", "title"},
+	Filterable:  []string{"score", "title"},
+	DefaultSort: "-score",
+	MaxPerPage:  50,
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    *Params
+		invalid []string
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			want:  &Params{Page: 1, PerPage: DefaultPerPage, Sort: []Sort{{Column: "score", Desc: true}}},
+		},
+		{
+			name:  "page, sort and filters",
+			query: "page=3&per_page=10&sort=title,-score&filter[title]=a&filter[score][gte]=5",
+			want: &Params{
+				Page: 3, PerPage: 10,
+				Sort:    []Sort{{Column: "title"}, {Column: "score", Desc: true}},
+				Filters: []Filter{{Column: "title", Operator: Eq, Value: "a"}, {Column: "score", Operator: Gte, Value: "5"}},
+			},
+		},
+		{
+			name:  "first cursor page",
+			query: "cursor=&sort=",
+			want:  &Params{Page: 1, PerPage: DefaultPerPage, UseCursor: true},
+		},
+		{name: "page below one", query: "page=0", invalid: []string{"page"}},
+		{name: "per page above the maximum", query: "per_page=51", invalid: []string{"per_page"}},
+		{name: "page with cursor", query: "page=2&cursor=", invalid: []string{"page"}},
+		{name: "sort outside the whitelist", query: "sort=password", invalid: []string{"sort"}},
+		{name: "filter outside the whitelist", query: "filter[password]=x", invalid: []string{"filter[password]"}},
+		{name: "unknown operator", query: "filter[score][between]=1", invalid: []string{"filter[score][between]"}},
+		{name: "malformed filter", query: "filter=x", invalid: []string{"filter"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := Parse(httptest.NewRequest(http.MethodGet, "/posts?"+tt.query, nil), testOptions)
+			if tt.invalid != nil {
+				var p *problem.Problem
+				if !errors.As(err, &p) || p.Status != http.StatusBadRequest {
+					t.Fatalf("got error %v, want a 400 problem", err)
+				}
+				for _, key := range tt.invalid {
+					if len(p.Errors[key]) == 0 {
+						t.Errorf("no error reported for %s: %v", key, p.Errors)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Filters come from a map, so their order is not fixed.
+			if len(params.Filters) == 2 && params.Filters[0].Column == "score" {
+				params.Filters[0], params.Filters[1] = params.Filters[1], params.Filters[0]
+			}
+			if !reflect.DeepEqual(params, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", params, tt.want)
+			}
+		})
+	}
+}
+
+type testPost struct {
+	ID    uint
+	Title string
+	Score int
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection to :memory: opens a new, empty database.
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(&testPost{}); err != nil {
+		t.Fatal(err)
+	}
+	// Scores tie so that pages must break ties by primary key.
+	for i, score := range []int{5, 3, 5, 1, 3, 5, 2} {
+		if err := db.Create(&testPost{Title: string(rune('a' + i)), Score: score}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func ids(posts []testPost) []uint {
+	ids := []uint{}
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+	return ids
+}
+
+func parse(t *testing.T, query string) *Params {
+	t.Helper()
+	params, err := Parse(httptest.NewRequest(http.MethodGet, "/posts?"+query, nil), testOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return params
+}
+
+func TestPaginateOffset(t *testing.T) {
+	db := newTestDB(t)
+
+	tests := []struct {
+		query string
+		ids   []uint
+		meta  Meta
+	}{
+		{query: "per_page=3", ids: []uint{1, 3, 6}, meta: Meta{Page: 1, PerPage: 3, Total: 7, TotalPages: 3}},
+		{query: "per_page=3&page=2", ids: []uint{2, 5, 7}, meta: Meta{Page: 2, PerPage: 3, Total: 7, TotalPages: 3}},
+		{query: "per_page=3&page=4", ids: []uint{}, meta: Meta{Page: 4, PerPage: 3, Total: 7, TotalPages: 3}},
+		{query: "filter[score][gte]=3&sort=title", ids: []uint{1, 2, 3, 5, 6}, meta: Meta{Page: 1, PerPage: DefaultPerPage, Total: 5, TotalPages: 1}},
+		{query: "filter[score][in]=1,2", ids: []uint{7, 4}, meta: Meta{Page: 1, PerPage: DefaultPerPage, Total: 2, TotalPages: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var posts []testPost
+			meta, err := Paginate(db, parse(t, tt.query), &posts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ids(posts); !reflect.DeepEqual(got, tt.ids) {
+				t.Errorf("ids = %v, want %v", got, tt.ids)
+			}
+			if *meta != tt.meta {
+				t.Errorf("meta = %+v, want %+v", *meta, tt.meta)
+			}
+		})
+	}
+}
+
+func TestPaginateCursor(t *testing.T) {
+	db := newTestDB(t)
+
+	var seen []uint
+	cursor := ""
+	for page := 0; page < 5; page++ {
+		var posts []testPost
+		meta, err := Paginate(db, parse(t, "per_page=3&cursor="+url.QueryEscape(cursor)), &posts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen = append(seen, ids(posts)...)
+
+		if page == 0 {
+			// A row inserted ahead of the cursor does not shift later pages.
+			if err := db.Create(&testPost{Title: "new", Score: 9}).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+		if meta.NextCursor == "" {
+			break
+		}
+		cursor = meta.NextCursor
+	}
+
+	if want := []uint{1, 3, 6, 2, 5, 7, 4}; !reflect.DeepEqual(seen, want) {
+		t.Errorf("pages held %v, want %v", seen, want)
+	}
+}
+
+func TestPaginateInvalidCursor(t *testing.T) {
+	db := newTestDB(t)
+
+	for _, cursor := range []string{"not-base64!", "W10", "WyJ4Il0"} {
+		var posts []testPost
+		_, err := Paginate(db, parse(t, "cursor="+cursor), &posts)
+		var p *problem.Problem
+		if !errors.As(err, &p) || p.Status != http.StatusBadRequest {
+			t.Errorf("cursor %q: got error %v, want a 400 problem", cursor, err)
+		}
+	}
+}
+
+func TestMetaLinks(t *testing.T) {
+	u, _ := url.Parse("/posts?per_page=3&page=2")
+	meta := &Meta{Page: 2, PerPage: 3, Total: 7, TotalPages: 3}
+
+	want := `</posts?page=1&per_page=3>; rel="first", </posts?page=1&per_page=3>; rel="prev", ` +
+		`</posts?page=3&per_page=3>; rel="next", </posts?page=3&per_page=3>; rel="last"`
+	if got := meta.Links(u); got != want {
+		t.Errorf("Links() = %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,211 @@
+package query
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"github.com/ThreadBolt/threadbolt/pkg/problem"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Filter is a GORM scope restricting the query to the request's filters:
+//
+//	db.Scopes(params.Filter).Find(&posts)
+func (p *Params) Filter(db *gorm.DB) *gorm.DB {
+	if len(p.Filters) == 0 {
+		return db
+	}
+	s, err := modelSchema(db)
+	if err != nil {
+		db.AddError(err)
+		return db
+	}
+
+	for _, f := range p.Filters {
+		field := s.LookUpField(f.Column)
+		if field == nil {
+			db.AddError(fmt.Errorf("failed to filter %s: no column %s", s.Name, f.Column))
+			return db
+		}
+		column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+
+		if f.Operator == In {
+			var values []interface{}
+			for _, raw := range strings.Split(f.Value, ",") {
+				value, err := convert(field, raw)
+				if err != nil {
+					db.AddError(invalidFilter(f, err))
+					return db
+				}
+				values = append(values, value)
+			}
+			db = db.Where(clause.IN{Column: column, Values: values})
+			continue
+		}
+
+		value, err := convert(field, f.Value)
+		if f.Operator == Like {
+			value, err = f.Value, nil
+		}
+		if err != nil {
+			db.AddError(invalidFilter(f, err))
+			return db
+		}
+
+		var expr clause.Expression
+		switch f.Operator {
+		case Ne:
+			expr = clause.Neq{Column: column, Value: value}
+		case Gt:
+			expr = clause.Gt{Column: column, Value: value}
+		case Gte:
+			expr = clause.Gte{Column: column, Value: value}
+		case Lt:
+			expr = clause.Lt{Column: column, Value: value}
+		case Lte:
+			expr = clause.Lte{Column: column, Value: value}
+		case Like:
+			expr = clause.Like{Column: column, Value: value}
+		default:
+			expr = clause.Eq{Column: column, Value: value}
+		}
+		db = db.Where(expr)
+	}
+	return db
+}
+
+// Order is a GORM scope ordering the query by the request's sort, then by
+// primary key so that pages are stable.
+func (p *Params) Order(db *gorm.DB) *gorm.DB {
+	s, err := modelSchema(db)
+	if err != nil {
+		db.AddError(err)
+		return db
+	}
+
+	ordering, err := p.ordering(s)
+	if err != nil {
+		db.AddError(err)
+		return db
+	}
+	for _, o := range ordering {
+		db = db.Order(clause.OrderByColumn{
+			Column: clause.Column{Table: clause.CurrentTable, Name: o.field.DBName},
+			Desc:   o.Desc,
+		})
+	}
+	return db
+}
+
+type orderField struct {
+	Sort
+	field *schema.Field
+}
+
+// ordering resolves the request's sort to the model's fields and appends the
+// primary key.
+func (p *Params) ordering(s *schema.Schema) ([]orderField, error) {
+	var ordering []orderField
+	hasPrimaryKey := false
+	for _, sort := range p.Sort {
+		field := s.LookUpField(sort.Column)
+		if field == nil {
+			return nil, fmt.Errorf("failed to sort %s: no column %s", s.Name, sort.Column)
+		}
+		hasPrimaryKey = hasPrimaryKey || field == s.PrioritizedPrimaryField
+		ordering = append(ordering, orderField{Sort: sort, field: field})
+	}
+
+	if !hasPrimaryKey && s.PrioritizedPrimaryField != nil {
+		primaryKey := s.PrioritizedPrimaryField
+		ordering = append(ordering, orderField{Sort: Sort{Column: primaryKey.DBName}, field: primaryKey})
+	}
+	return ordering, nil
+}
+
+// modelSchema parses the model or destination of db's statement.
+func modelSchema(db *gorm.DB) (*schema.Schema, error) {
+	model := db.Statement.Model
+	if model == nil {
+		model = db.Statement.Dest
+	}
+	if model == nil {
+		return nil, fmt.Errorf("failed to apply query: no model set")
+	}
+	return parseSchema(db, model)
+}
+
+func parseSchema(db *gorm.DB, model interface{}) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, fmt.Errorf("failed to parse model %T: %w", model, err)
+	}
+	return stmt.Schema, nil
+}
+
+// convert parses raw as a value of field's type so that it compares
+// correctly on every database.
+func convert(field *schema.Field, raw string) (interface{}, error) {
+	t := field.FieldType
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		if value, err := time.Parse(time.RFC3339, raw); err == nil {
+			return value, nil
+		}
+		value, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a date or RFC 3339 time")
+		}
+		return value, nil
+	case t.Kind() == reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be true or false")
+		}
+		return value, nil
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return value, nil
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64:
+		value, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a non-negative integer")
+		}
+		return value, nil
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return value, nil
+	default:
+		return raw, nil
+	}
+}
+
+func invalidFilter(f Filter, err error) error {
+	key := fmt.Sprintf("filter[%s]", f.Column)
+	if f.Operator != Eq {
+		key += fmt.Sprintf("[%s]", f.Operator)
+	}
+
+	p := problem.New(http.StatusBadRequest, "invalid query parameters")
+	p.Errors = map[string][]string{key: {err.Error()}}
+	return p
+}
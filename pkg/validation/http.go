@@ -0,0 +1,66 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ThreadBolt/threadbolt/pkg/middleware"
+	"github.com/ThreadBolt/threadbolt/pkg/problem"
+)
+
+// BindJSON decodes the request body into dst and validates it. On failure
+// it writes a 400 for malformed JSON, a 413 for a body over the BodyLimit
+// middleware's limit or a 422 listing the invalid fields, and returns false.
+//
+//	var req models.CreatePostRequest
+//	if !validation.BindJSON(w, r, &req) {
+//		return
+//	}
+func BindJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeProblem(w, r, problem.New(http.StatusRequestEntityTooLarge, "request body too large"))
+		} else {
+			writeProblem(w, r, problem.New(http.StatusBadRequest, "request body is not valid JSON"))
+		}
+		return false
+	}
+
+	if err := Validate(r.Context(), dst); err != nil {
+		writeProblem(w, r, Problem(err))
+		return false
+	}
+	return true
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, p *problem.Problem) {
+	p.Instance = r.URL.Path
+	p.RequestID = middleware.GetRequestID(r.Context())
+	problem.Write(w, p)
+}
+
+// WriteErrors writes err as a 422 problem whose errors member lists the
+// messages of each invalid field:
+//
+//	{"type": "about:blank", "title": "Unprocessable Entity", "status": 422,
+//	 "detail": "validation failed", "errors": {"email": ["must be a valid email address"]}}
+//
+// Errors other than validation Errors are written as a 500 without detail.
+func WriteErrors(w http.ResponseWriter, err error) {
+	problem.Write(w, Problem(err))
+}
+
+// Problem converts validation Errors to a 422 problem and any other error
+// to a 500 problem that does not disclose it.
+func Problem(err error) *problem.Problem {
+	var errs Errors
+	if !errors.As(err, &errs) {
+		return problem.New(http.StatusInternalServerError, "")
+	}
+
+	p := problem.New(http.StatusUnprocessableEntity, "validation failed")
+	p.Errors = errs.Fields()
+	return p
+}
@@ -0,0 +1,141 @@
+package validation
+
+import (
+	"context"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var builtinRules = map[string]rule{
+	"required": {check: required, message: "is required"},
+	"email":    {check: email, message: "must be a valid email address"},
+	"url":      {check: isURL, message: "must be a valid URL"},
+	"uuid":     {check: matches(uuidPattern), message: "must be a valid UUID"},
+	"alpha":    {check: allRunes(unicode.IsLetter), message: "must contain only letters"},
+	"alphanum": {check: allRunes(isAlphanumeric), message: "must contain only letters and digits"},
+	"numeric":  {check: numeric, message: "must be a number"},
+	"oneof":    {check: oneOf, message: "must be one of: {param}"},
+	"min":      {check: compare(func(n, p float64) bool { return n >= p }), message: "must be at least {param} characters|must be at least {param}"},
+	"max":      {check: compare(func(n, p float64) bool { return n <= p }), message: "must be at most {param} characters|must be at most {param}"},
+	"len":      {check: compare(func(n, p float64) bool { return n == p }), message: "must be exactly {param} characters|must be {param}"},
+	"gt":       {check: compare(func(n, p float64) bool { return n > p }), message: "must be longer than {param} characters|must be greater than {param}"},
+	"lt":       {check: compare(func(n, p float64) bool { return n < p }), message: "must be shorter than {param} characters|must be less than {param}"},
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func required(ctx context.Context, field reflect.Value, param string) bool {
+	if field.Kind() == reflect.String {
+		return strings.TrimSpace(field.String()) != ""
+	}
+	return !isEmpty(field)
+}
+
+func email(ctx context.Context, field reflect.Value, param string) bool {
+	if field.Kind() != reflect.String {
+		return false
+	}
+	address, err := mail.ParseAddress(field.String())
+	return err == nil && address.Address == field.String()
+}
+
+func isURL(ctx context.Context, field reflect.Value, param string) bool {
+	if field.Kind() != reflect.String {
+		return false
+	}
+	parsed, err := url.ParseRequestURI(field.String())
+	return err == nil && parsed.Scheme != "" && parsed.Host != ""
+}
+
+func numeric(ctx context.Context, field reflect.Value, param string) bool {
+	switch field.Kind() {
+	case reflect.String:
+		_, err := strconv.ParseFloat(field.String(), 64)
+		return err == nil
+	default:
+		_, ok := number(field)
+		return ok
+	}
+}
+
+func oneOf(ctx context.Context, field reflect.Value, param string) bool {
+	value := field.String()
+	if field.Kind() != reflect.String {
+		n, ok := number(field)
+		if !ok {
+			return false
+		}
+		value = strconv.FormatFloat(n, 'f', -1, 64)
+	}
+
+	for _, option := range strings.Fields(param) {
+		if option == value {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(pattern *regexp.Regexp) Rule {
+	return func(ctx context.Context, field reflect.Value, param string) bool {
+		return field.Kind() == reflect.String && pattern.MatchString(field.String())
+	}
+}
+
+func allRunes(valid func(rune) bool) Rule {
+	return func(ctx context.Context, field reflect.Value, param string) bool {
+		if field.Kind() != reflect.String {
+			return false
+		}
+		for _, r := range field.String() {
+			if !valid(r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func isAlphanumeric(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// compare checks the length of strings, slices and maps, or the value of
+// numbers, against the rule's numeric parameter.
+func compare(ok func(n, param float64) bool) Rule {
+	return func(ctx context.Context, field reflect.Value, param string) bool {
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return false
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			return ok(float64(utf8.RuneCountInString(field.String())), limit)
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return ok(float64(field.Len()), limit)
+		default:
+			n, isNumber := number(field)
+			return isNumber && ok(n, limit)
+		}
+	}
+}
+
+func number(field reflect.Value) (float64, bool) {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), true
+	default:
+		return 0, false
+	}
+}
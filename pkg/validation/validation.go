@@ -0,0 +1,281 @@
+// Package validation validates structs against rules declared in validate
+// struct tags:
+//
+//	type CreateUserRequest struct {
+//		Name  string `json:"name" validate:"required,min=3"`
+//		Email string `json:"email" validate:"required,email"`
+//	}
+//
+// Rules run in order and stop at the first failure of a field. Nil pointers
+// are only checked by required, as are zero values of fields whose tag
+// starts with omitempty, as in `validate:"omitempty,email"`; every other
+// value is checked by all of its rules, so min=1 rejects 0.
+package validation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThreadBolt/threadbolt/pkg/di"
+)
+
+// Rule reports whether field satisfies the rule; param is the text after
+// "=" in the tag, as in "min=3". ctx is the context passed to Validate, from
+// which rules can reach request-scoped services with di.FromContext.
+type Rule func(ctx context.Context, field reflect.Value, param string) bool
+
+// FieldError describes a field that failed a rule.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Errors lists every field that failed validation.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Field + " " + fieldErr.Message
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// Fields groups the error messages by field.
+func (e Errors) Fields() map[string][]string {
+	fields := make(map[string][]string)
+	for _, fieldErr := range e {
+		fields[fieldErr.Field] = append(fields[fieldErr.Field], fieldErr.Message)
+	}
+	return fields
+}
+
+type rule struct {
+	check   Rule
+	message string
+}
+
+// Validator holds the rules available to validate tags.
+type Validator struct {
+	rules map[string]rule
+	mutex sync.RWMutex
+}
+
+// Default is used when a context carries no validator.
+var Default = New()
+
+// New returns a validator with the built-in rules.
+func New() *Validator {
+	v := &Validator{rules: make(map[string]rule)}
+	for name, builtin := range builtinRules {
+		v.rules[name] = builtin
+	}
+	return v
+}
+
+// RegisterRule adds or replaces a rule. message is reported for failing
+// fields, with "{param}" replaced by the rule's parameter.
+func (v *Validator) RegisterRule(name string, check Rule, message string) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.rules[name] = rule{check: check, message: message}
+}
+
+// Validate checks s, a struct or pointer to one, and returns Errors listing
+// every failing field, or nil. Nested structs and slices of structs are
+// validated too, with fields named like "address.city" and "items[0].name".
+func (v *Validator) Validate(ctx context.Context, s interface{}) error {
+	value := reflect.ValueOf(s)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("validation target must be a struct, got %T", s)
+	}
+
+	var errs Errors
+	if err := v.validateStruct(ctx, value, "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (v *Validator) validateStruct(ctx context.Context, value reflect.Value, prefix string, errs *Errors) error {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := prefix + fieldName(field)
+		fieldValue := value.Field(i)
+
+		if tag := field.Tag.Get("validate"); tag != "" && tag != "-" {
+			if err := v.validateField(ctx, fieldValue, name, tag, errs); err != nil {
+				return err
+			}
+		}
+
+		if field.Anonymous && fieldValue.Kind() == reflect.Struct {
+			// Promote the fields of embedded structs.
+			if err := v.validateStruct(ctx, fieldValue, prefix, errs); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := v.validateNested(ctx, fieldValue, name, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *Validator) validateNested(ctx context.Context, value reflect.Value, name string, errs *Errors) error {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if value.Type() == reflect.TypeOf(time.Time{}) {
+			return nil
+		}
+		return v.validateStruct(ctx, value, name+".", errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := v.validateNested(ctx, value.Index(i), fmt.Sprintf("%s[%d]", name, i), errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *Validator) validateField(ctx context.Context, value reflect.Value, name, tag string, errs *Errors) error {
+	type entry struct {
+		name  string
+		param string
+		rule  rule
+	}
+
+	var entries []entry
+	omitEmpty := false
+	for _, part := range strings.Split(tag, ",") {
+		ruleName, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if ruleName == "" {
+			continue
+		}
+		if ruleName == "omitempty" {
+			omitEmpty = true
+			continue
+		}
+
+		v.mutex.RLock()
+		r, ok := v.rules[ruleName]
+		v.mutex.RUnlock()
+		if !ok {
+			return fmt.Errorf("unknown validation rule '%s' on field %s", ruleName, name)
+		}
+		entries = append(entries, entry{name: ruleName, param: param, rule: r})
+	}
+
+	skip := isNil(value) || (omitEmpty && isEmpty(value))
+	for _, e := range entries {
+		if skip && e.name != "required" {
+			continue
+		}
+
+		if !e.rule.check(ctx, indirect(value), e.param) {
+			*errs = append(*errs, FieldError{
+				Field:   name,
+				Rule:    e.name,
+				Param:   e.param,
+				Message: formatMessage(messageFor(e.rule.message, value), e.param),
+			})
+			return nil
+		}
+	}
+	return nil
+}
+
+// messageFor picks the length or value form of messages such as
+// "must be at least {param} characters|must be at least {param}".
+func messageFor(message string, value reflect.Value) string {
+	lengthForm, valueForm, found := strings.Cut(message, "|")
+	if !found {
+		return message
+	}
+	switch indirect(value).Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return lengthForm
+	default:
+		return valueForm
+	}
+}
+
+func formatMessage(message, param string) string {
+	if param == "1" {
+		message = strings.ReplaceAll(message, "{param} characters", "1 character")
+	}
+	return strings.ReplaceAll(message, "{param}", param)
+}
+
+func fieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return field.Name
+}
+
+func isEmpty(value reflect.Value) bool {
+	return !value.IsValid() || value.IsZero()
+}
+
+// isNil reports whether value is absent: invalid, or a nil pointer or
+// interface.
+func isNil(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	}
+	return false
+}
+
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	return value
+}
+
+// FromContext returns the validator registered as "validator" in the DI
+// container carried by ctx, falling back to Default.
+func FromContext(ctx context.Context) *Validator {
+	if c := di.FromContext(ctx); c != nil {
+		var v *Validator
+		if err := c.GetTyped("validator", &v); err == nil {
+			return v
+		}
+	}
+	return Default
+}
+
+// Validate checks s with the validator of ctx; see Validator.Validate.
+func Validate(ctx context.Context, s interface{}) error {
+	return FromContext(ctx).Validate(ctx, s)
+}
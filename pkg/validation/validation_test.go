@@ -0,0 +1,178 @@
+package validation
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	age := 0
+
+	tests := []struct {
+		name  string
+		value interface{}
+		// fails maps each failing field to the rule it failed.
+		fails map[string]string
+	}{
+		{
+			name: "required",
+			value: struct {
+				Name  string `json:"name" validate:"required"`
+				Count int    `validate:"required"`
+				Tags  []int  `json:"tags" validate:"required"`
+			}{},
+			fails: map[string]string{"name": "required", "Count": "required", "tags": "required"},
+		},
+		{
+			name: "formats",
+			value: struct {
+				Email string `json:"email" validate:"email"`
+				URL   string `json:"url" validate:"url"`
+				ID    string `json:"id" validate:"uuid"`
+				Code  string `json:"code" validate:"alphanum"`
+			}{Email: "Ann <ann@example.com>", URL: "example.com", ID: "123", Code: "a-1"},
+			fails: map[string]string{"email": "email", "url": "url", "id": "uuid", "code": "alphanum"},
+		},
+		{
+			name: "valid formats",
+			value: struct {
+				Email  string `json:"email" validate:"email"`
+				URL    string `json:"url" validate:"url"`
+				ID     string `json:"id" validate:"uuid"`
+				Status string `json:"status" validate:"oneof=draft published"`
+			}{Email: "ann@example.com", URL: "https://example.com", ID: "123e4567-e89b-12d3-a456-426614174000", Status: "draft"},
+		},
+		{
+			name: "oneof",
+			value: struct {
+				Status string `json:"status" validate:"oneof=draft published"`
+			}{Status: "deleted"},
+			fails: map[string]string{"status": "oneof"},
+		},
+		{
+			name: "lengths and values",
+			value: struct {
+				Short string  `json:"short" validate:"min=3"`
+				Long  string  `json:"long" validate:"max=3"`
+				Code  string  `json:"code" validate:"len=2"`
+				Count int     `json:"count" validate:"gt=1"`
+				Ratio float64 `json:"ratio" validate:"lt=1"`
+				Runes string  `json:"runes" validate:"max=2"`
+			}{Short: "ab", Long: "abcd", Code: "abc", Count: 1, Ratio: 1, Runes: "éé"},
+			fails: map[string]string{"short": "min", "long": "max", "code": "len", "count": "gt", "ratio": "lt"},
+		},
+		{
+			name: "zero values are checked",
+			value: struct {
+				Quantity int    `json:"quantity" validate:"min=1"`
+				Code     string `json:"code" validate:"len=2"`
+			}{},
+			fails: map[string]string{"quantity": "min", "code": "len"},
+		},
+		{
+			name: "omitempty skips zero values only",
+			value: struct {
+				Website string `json:"website" validate:"omitempty,url"`
+				Email   string `json:"email" validate:"omitempty,email"`
+			}{Email: "nope"},
+			fails: map[string]string{"email": "email"},
+		},
+		{
+			name: "nil pointers are only checked by required",
+			value: struct {
+				Age   *int    `json:"age" validate:"min=1"`
+				Email *string `json:"email" validate:"required,email"`
+			}{},
+			fails: map[string]string{"email": "required"},
+		},
+		{
+			name: "pointers to zero values are checked",
+			value: struct {
+				Age *int `json:"age" validate:"min=1"`
+			}{Age: &age},
+			fails: map[string]string{"age": "min"},
+		},
+		{
+			name: "first failing rule of a field",
+			value: struct {
+				Name string `json:"name" validate:"required,min=3"`
+			}{},
+			fails: map[string]string{"name": "required"},
+		},
+		{
+			name: "nested structs and slices",
+			value: struct {
+				Address struct {
+					City string `json:"city" validate:"required"`
+				} `json:"address"`
+				Items []struct {
+					Name string `json:"name" validate:"required"`
+				} `json:"items"`
+			}{Items: make([]struct {
+				Name string `json:"name" validate:"required"`
+			}, 2)},
+			fails: map[string]string{"address.city": "required", "items[0].name": "required", "items[1].name": "required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Validate(context.Background(), tt.value)
+
+			fails := map[string]string{}
+			if err != nil {
+				errs, ok := err.(Errors)
+				if !ok {
+					t.Fatalf("got error %v, want Errors", err)
+				}
+				for _, fieldErr := range errs {
+					fails[fieldErr.Field] = fieldErr.Rule
+				}
+			}
+			if tt.fails == nil {
+				tt.fails = map[string]string{}
+			}
+			if !reflect.DeepEqual(fails, tt.fails) {
+				t.Errorf("failed rules = %v, want %v", fails, tt.fails)
+			}
+		})
+	}
+}
+
+func TestMessages(t *testing.T) {
+	err := New().Validate(context.Background(), struct {
+		Name     string `json:"name" validate:"min=1"`
+		Quantity int    `json:"quantity" validate:"min=5"`
+	}{})
+
+	want := map[string][]string{
+		"name":     {"must be at least 1 character"},
+		"quantity": {"must be at least 5"},
+	}
+	if got := err.(Errors).Fields(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields() = %v, want %v", got, want)
+	}
+}
+
+func TestRegisterRule(t *testing.T) {
+	v := New()
+	v.RegisterRule("even", func(ctx context.Context, field reflect.Value, param string) bool {
+		return field.Int()%2 == 0
+	}, "must be even")
+
+	err := v.Validate(context.Background(), struct {
+		N int `json:"n" validate:"even"`
+	}{N: 3})
+	if errs, ok := err.(Errors); !ok || len(errs) != 1 || errs[0].Message != "must be even" {
+		t.Errorf("got error %v, want n must be even", err)
+	}
+
+	err = v.Validate(context.Background(), struct {
+		N int `validate:"odd"`
+	}{})
+	if err == nil || !strings.Contains(err.Error(), "unknown validation rule 'odd'") {
+		t.Errorf("got error %v, want an unknown rule error", err)
+	}
+}
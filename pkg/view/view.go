@@ -0,0 +1,120 @@
+// Package view renders HTML pages from html/template files.
+//
+// Pages live in pages/ under the views directory and are executed through
+// the "layout" template, which the files in layouts/ define. Templates in
+// partials/ are available to every page:
+//
+//	templates/layouts/app.html   {{define "layout"}}...{{template "content" .}}...{{end}}
+//	templates/partials/nav.html  {{define "nav"}}...{{end}}
+//	templates/pages/home.html    {{define "content"}}...{{end}}
+//
+// A page is executed by itself when no layout is defined.
+package view
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"path/filepath"
+	"sync"
+)
+
+// Config configures Views.
+type Config struct {
+	// Dir is the views directory. It defaults to "templates".
+	Dir string
+	// Layout is the template pages are executed through. It defaults to
+	// "layout".
+	Layout string
+	// Reload parses the templates on every render instead of once, so that
+	// edits show up without a restart during development.
+	Reload bool
+	// Funcs are made available to every template.
+	Funcs template.FuncMap
+}
+
+// Views renders the pages in a views directory.
+type Views struct {
+	config Config
+
+	mutex sync.RWMutex
+	pages map[string]*page
+}
+
+type page struct {
+	tmpl  *template.Template
+	entry string
+}
+
+func New(cfg Config) *Views {
+	if cfg.Dir == "" {
+		cfg.Dir = "templates"
+	}
+	if cfg.Layout == "" {
+		cfg.Layout = "layout"
+	}
+	return &Views{config: cfg, pages: make(map[string]*page)}
+}
+
+// Render executes the page name, such as "home" for pages/home.html or
+// "posts/index" for pages/posts/index.html, and writes it with status.
+// Nothing is written if the page fails to render.
+func (v *Views) Render(w http.ResponseWriter, status int, name string, data interface{}) error {
+	p, err := v.page(name)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := p.tmpl.ExecuteTemplate(&buf, p.entry, data); err != nil {
+		return fmt.Errorf("failed to render page %s: %w", name, err)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+func (v *Views) page(name string) (*page, error) {
+	if !v.config.Reload {
+		v.mutex.RLock()
+		p, ok := v.pages[name]
+		v.mutex.RUnlock()
+		if ok {
+			return p, nil
+		}
+	}
+
+	tmpl := template.New(name).Funcs(v.config.Funcs)
+	for _, pattern := range []string{"layouts/*.html", "partials/*.html"} {
+		files, err := filepath.Glob(filepath.Join(v.config.Dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("failed to find views: %w", err)
+		}
+		if len(files) == 0 {
+			continue
+		}
+		if _, err := tmpl.ParseFiles(files...); err != nil {
+			return nil, fmt.Errorf("failed to parse views: %w", err)
+		}
+	}
+
+	file := filepath.Join(v.config.Dir, "pages", filepath.FromSlash(name)+".html")
+	if _, err := tmpl.ParseFiles(file); err != nil {
+		return nil, fmt.Errorf("failed to parse page %s: %w", name, err)
+	}
+
+	p := &page{tmpl: tmpl, entry: v.config.Layout}
+	if tmpl.Lookup(p.entry) == nil {
+		p.entry = filepath.Base(file)
+	}
+
+	if !v.config.Reload {
+		v.mutex.Lock()
+		v.pages[name] = p
+		v.mutex.Unlock()
+	}
+	return p, nil
+}